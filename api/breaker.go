@@ -0,0 +1,134 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrHubUnreachable dikembalikan tanpa melakukan request ketika circuit breaker
+// untuk hub tujuan sedang terbuka.
+var ErrHubUnreachable = errors.New("hub unreachable")
+
+// HubUnreachableError membungkus ErrHubUnreachable beserta base URL hub yang gagal.
+type HubUnreachableError struct {
+	BaseURL string
+	Until   time.Time
+}
+
+func (e *HubUnreachableError) Error() string {
+	return fmt.Sprintf("hub %s unreachable (circuit breaker terbuka sampai %s)", e.BaseURL, e.Until.Format("15:04:05"))
+}
+
+func (e *HubUnreachableError) Unwrap() error {
+	return ErrHubUnreachable
+}
+
+// circuitBreaker menghitung kegagalan beruntun per base URL hub. Hub yang
+// berbagi host di balik path berbeda dihitung terpisah. Setelah threshold
+// tercapai, request ke hub tersebut langsung ditolak selama cooldown, lalu satu
+// request percobaan diizinkan untuk menentukan apakah hub sudah pulih.
+type circuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	mu   sync.Mutex
+	hubs map[string]*breakerState
+}
+
+type breakerState struct {
+	failures  int
+	openUntil time.Time
+	probing   bool
+}
+
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		hubs:      make(map[string]*breakerState),
+	}
+}
+
+func (b *circuitBreaker) state(hub string) *breakerState {
+	st, ok := b.hubs[hub]
+	if !ok {
+		st = &breakerState{}
+		b.hubs[hub] = st
+	}
+	return st
+}
+
+// Allow mengembalikan error jika request ke hub harus ditolak.
+func (b *circuitBreaker) Allow(hub string) error {
+	if b == nil || b.threshold <= 0 {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	st := b.state(hub)
+	if st.failures < b.threshold {
+		return nil
+	}
+	if time.Now().Before(st.openUntil) || st.probing {
+		return &HubUnreachableError{BaseURL: hub, Until: st.openUntil}
+	}
+	// Cooldown selesai: izinkan satu request percobaan (half-open).
+	st.probing = true
+	return nil
+}
+
+func (b *circuitBreaker) Success(hub string) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.hubs, hub)
+}
+
+// Abort melepas request percobaan yang dibatalkan pemanggil tanpa mengubah status hub.
+func (b *circuitBreaker) Abort(hub string) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if st, ok := b.hubs[hub]; ok {
+		st.probing = false
+	}
+}
+
+func (b *circuitBreaker) Failure(hub string) {
+	if b == nil || b.threshold <= 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	st := b.state(hub)
+	st.failures++
+	st.probing = false
+	if st.failures >= b.threshold {
+		st.openUntil = time.Now().Add(b.cooldown)
+	}
+}
+
+// IsOpen menandakan hub sedang dianggap tidak dapat dijangkau.
+func (b *circuitBreaker) IsOpen(hub string) bool {
+	if b == nil || b.threshold <= 0 {
+		return false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	st, ok := b.hubs[hub]
+	return ok && st.failures >= b.threshold && time.Now().Before(st.openUntil)
+}
+
+// breakerKey menormalkan base URL hub sebagai kunci circuit breaker.
+func breakerKey(baseURL string) string {
+	return strings.TrimRight(baseURL, "/")
+}
@@ -0,0 +1,163 @@
+package api
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Nama endpoint G1x yang di-cache. Dipakai sebagai kunci TTL di ClientOptions.CacheTTLs.
+const (
+	EndpointIpcnStatus       = "ipcn_status"
+	EndpointIptxTraffic      = "iptx_traffic"
+	EndpointOnlineUT         = "online_ut"
+	EndpointIpcnSensorStatus = "ipcn_sensor_status"
+	EndpointDeviceProperties = "device_properties"
+	EndpointCnBeacon         = "cn_beacon"
+	EndpointBeamStatus       = "beam_status"
+	EndpointTerminalStatus   = "terminal_status"
+)
+
+// DefaultCacheTTLs adalah TTL bawaan per endpoint. Nilai 0 menonaktifkan cache
+// untuk endpoint tersebut, tetapi request yang bersamaan tetap digabung.
+var DefaultCacheTTLs = map[string]time.Duration{
+	EndpointIpcnStatus:       30 * time.Second,
+	EndpointIptxTraffic:      time.Minute,
+	EndpointOnlineUT:         time.Minute,
+	EndpointIpcnSensorStatus: 30 * time.Second,
+	EndpointDeviceProperties: 30 * time.Second,
+	EndpointCnBeacon:         time.Minute,
+	EndpointBeamStatus:       time.Minute,
+	EndpointTerminalStatus:   time.Minute,
+}
+
+// responseCache menyimpan body respons mentah per kunci dan menggabungkan
+// request yang sedang berjalan untuk kunci yang sama.
+type responseCache struct {
+	ttls map[string]time.Duration
+	// fetchTimeout membatasi fetch bersama yang berjalan terlepas dari ctx pemanggil.
+	fetchTimeout time.Duration
+
+	mu       sync.Mutex
+	entries  map[string]cacheEntry
+	inflight map[string]*inflightCall
+}
+
+type cacheEntry struct {
+	body      []byte
+	fetchedAt time.Time
+	expires   time.Time
+}
+
+type inflightCall struct {
+	done      chan struct{}
+	body      []byte
+	fetchedAt time.Time
+	err       error
+}
+
+func newResponseCache(overrides map[string]time.Duration, fetchTimeout time.Duration) *responseCache {
+	ttls := make(map[string]time.Duration, len(DefaultCacheTTLs))
+	for endpoint, ttl := range DefaultCacheTTLs {
+		ttls[endpoint] = ttl
+	}
+	for endpoint, ttl := range overrides {
+		ttls[endpoint] = ttl
+	}
+	return &responseCache{
+		ttls:         ttls,
+		fetchTimeout: fetchTimeout,
+		entries:      make(map[string]cacheEntry),
+		inflight:     make(map[string]*inflightCall),
+	}
+}
+
+// get mengembalikan body untuk key dari cache jika masih berlaku. Jika tidak,
+// fetch dipanggil sekali walaupun ada beberapa pemanggil yang bersamaan.
+// force melewati entri cache tetapi tetap bergabung dengan request yang berjalan.
+// Fetch bersama berjalan dengan context yang lepas dari pembatalan pemanggil
+// pertama (dibatasi fetchTimeout); setiap pemanggil hanya menunggu selama
+// ctx-nya sendiri masih berlaku.
+func (rc *responseCache) get(ctx context.Context, endpoint, key string, force bool, fetch func(ctx context.Context) ([]byte, error)) ([]byte, time.Time, error) {
+	rc.mu.Lock()
+	if entry, ok := rc.entries[key]; ok && !force && time.Now().Before(entry.expires) {
+		rc.mu.Unlock()
+		return entry.body, entry.fetchedAt, nil
+	}
+	call, ok := rc.inflight[key]
+	if !ok {
+		call = &inflightCall{done: make(chan struct{})}
+		rc.inflight[key] = call
+		go rc.run(ctx, endpoint, key, call, fetch)
+	}
+	rc.mu.Unlock()
+
+	select {
+	case <-call.done:
+		return call.body, call.fetchedAt, call.err
+	case <-ctx.Done():
+		return nil, time.Time{}, ctx.Err()
+	}
+}
+
+func (rc *responseCache) run(ctx context.Context, endpoint, key string, call *inflightCall, fetch func(ctx context.Context) ([]byte, error)) {
+	fetchCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), rc.fetchTimeout)
+	defer cancel()
+
+	call.body, call.err = fetch(fetchCtx)
+	call.fetchedAt = time.Now()
+
+	rc.mu.Lock()
+	delete(rc.inflight, key)
+	if ttl := rc.ttls[endpoint]; call.err == nil && ttl > 0 {
+		rc.entries[key] = cacheEntry{body: call.body, fetchedAt: call.fetchedAt, expires: call.fetchedAt.Add(ttl)}
+	}
+	rc.mu.Unlock()
+	close(call.done)
+}
+
+type fetchInfoKey struct{}
+type forceRefreshKey struct{}
+
+// FetchInfo mencatat umur data tertua yang dipakai selama satu perintah.
+type FetchInfo struct {
+	mu     sync.Mutex
+	oldest time.Time
+}
+
+// WithFetchInfo memasang pencatat umur data pada ctx.
+func WithFetchInfo(ctx context.Context) (context.Context, *FetchInfo) {
+	info := &FetchInfo{}
+	return context.WithValue(ctx, fetchInfoKey{}, info), info
+}
+
+// WithForceRefresh membuat semua request pada ctx melewati cache.
+func WithForceRefresh(ctx context.Context) context.Context {
+	return context.WithValue(ctx, forceRefreshKey{}, true)
+}
+
+// Oldest mengembalikan waktu pengambilan data tertua, atau nol jika belum ada data.
+func (f *FetchInfo) Oldest() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.oldest
+}
+
+func (f *FetchInfo) record(fetchedAt time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.oldest.IsZero() || fetchedAt.Before(f.oldest) {
+		f.oldest = fetchedAt
+	}
+}
+
+func isForceRefresh(ctx context.Context) bool {
+	force, _ := ctx.Value(forceRefreshKey{}).(bool)
+	return force
+}
+
+func recordFetch(ctx context.Context, fetchedAt time.Time) {
+	if info, ok := ctx.Value(fetchInfoKey{}).(*FetchInfo); ok {
+		info.record(fetchedAt)
+	}
+}
@@ -2,23 +2,73 @@ package api
 
 import (
 	"bytes"
+	"context"
+	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
+	"net"
 	"net/http"
-	"strings"
 	"sync"
 	"time"
 )
 
 type APIClient struct {
+	// Hub adalah nama hub (G1G/G1K/G1L) yang dilayani klien ini.
+	Hub        string
 	HTTPClient *http.Client
 	BaseURL    string
 	Email      string
 	Password   string
 	Token      string
 	mu         sync.Mutex
+
+	// tokenExpiry diambil dari klaim exp token. Nol berarti masa berlaku tidak diketahui.
+	tokenExpiry time.Time
+	// loginInflight adalah login yang sedang berjalan, nil jika tidak ada.
+	loginInflight *loginCall
+
+	maxRetries int
+	backoff    time.Duration
+	breaker    *circuitBreaker
+	cache      *responseCache
+}
+
+// ClientOptions mengatur timeout, retry, dan circuit breaker APIClient.
+// Retry dilakukan untuk error jaringan/timeout dan respons 5xx dengan backoff
+// eksponensial. Breaker terbuka per hub (base URL) setelah BreakerThreshold kegagalan
+// beruntun dan menolak request selama BreakerCooldown.
+type ClientOptions struct {
+	Timeout          time.Duration
+	MaxRetries       int
+	Backoff          time.Duration
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+
+	// CacheTTLs menimpa DefaultCacheTTLs per endpoint.
+	CacheTTLs map[string]time.Duration
+
+	// TLS dipakai untuk koneksi HTTPS ke hub. Nil berarti pengaturan bawaan.
+	TLS *tls.Config
+}
+
+// StatusError adalah respons non-200 dari API G1x.
+type StatusError struct {
+	StatusCode int
+	URL        string
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("menerima status code tidak OK: %d dari %s. Response: %s", e.StatusCode, e.URL, e.Body)
+}
+
+// IsUnauthorized menandakan err berasal dari respons 401.
+func IsUnauthorized(err error) bool {
+	var statusErr *StatusError
+	return errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusUnauthorized
 }
 
 type LoginRequest struct {
@@ -34,101 +84,321 @@ type LoginResponse struct {
 	Status  bool   `json:"status"`
 }
 
-func NewAPIClient(baseURL, email, password string) *APIClient {
+func NewAPIClient(baseURL, email, password string, opts ClientOptions) *APIClient {
+	httpClient := &http.Client{Timeout: opts.Timeout}
+	if opts.TLS != nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = opts.TLS
+		httpClient.Transport = transport
+	}
 	return &APIClient{
-		HTTPClient: &http.Client{Timeout: 60 * time.Second},
+		HTTPClient: httpClient,
 		BaseURL:    baseURL,
 		Email:      email,
 		Password:   password,
+		maxRetries: opts.MaxRetries,
+		backoff:    opts.Backoff,
+		breaker:    newCircuitBreaker(opts.BreakerThreshold, opts.BreakerCooldown),
+		cache:      newResponseCache(opts.CacheTTLs, fetchTimeout(opts)),
+	}
+}
+
+// fetchTimeout adalah batas waktu satu fetch bersama di cache, cukup untuk
+// login ulang dan semua retry dengan backoff.
+func fetchTimeout(opts ClientOptions) time.Duration {
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	retries := time.Duration(opts.MaxRetries)
+	return timeout*(retries+2) + opts.Backoff*(1<<retries)
+}
+
+// IsHubUnreachable menandakan circuit breaker untuk hub klien ini sedang terbuka.
+func (c *APIClient) IsHubUnreachable() bool {
+	return c.breaker.IsOpen(breakerKey(c.BaseURL))
+}
+
+func (c *APIClient) Login(ctx context.Context) error {
+	_, err := c.login(ctx)
+	return err
+}
+
+// currentToken mengembalikan token yang masih berlaku, login lebih dulu jika
+// token kosong atau akan kedaluwarsa dalam tokenRefreshMargin. Pemanggil yang
+// bersamaan menunggu satu login yang sama.
+func (c *APIClient) currentToken(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	token, expiry := c.Token, c.tokenExpiry
+	c.mu.Unlock()
+
+	if token != "" && (expiry.IsZero() || time.Now().Add(tokenRefreshMargin).Before(expiry)) {
+		return token, nil
+	}
+	if token != "" {
+		slog.Info("Token API akan kedaluwarsa, memperbarui token lebih awal...", "hub", c.Hub, "expires_at", expiry)
+	}
+	return c.login(ctx)
+}
+
+// refreshToken login ulang setelah 401, kecuali token sudah diperbarui oleh
+// pemanggil lain sejak staleToken dipakai.
+func (c *APIClient) refreshToken(ctx context.Context, staleToken string) (string, error) {
+	c.mu.Lock()
+	token := c.Token
+	c.mu.Unlock()
+
+	if token != "" && token != staleToken {
+		return token, nil
+	}
+	return c.login(ctx)
+}
+
+// loginCall adalah satu login yang sedang berjalan, dibagi ke semua pemanggil
+// yang membutuhkan token pada saat yang sama.
+type loginCall struct {
+	done  chan struct{}
+	token string
+	err   error
+}
+
+// login menjalankan paling banyak satu login sekaligus tanpa menahan c.mu
+// selama request HTTP. Hasilnya, termasuk kegagalan, dibagi ke semua pemanggil
+// yang menunggu; setiap pemanggil hanya menunggu selama ctx-nya masih berlaku.
+func (c *APIClient) login(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	call := c.loginInflight
+	if call == nil {
+		call = &loginCall{done: make(chan struct{})}
+		c.loginInflight = call
+		go c.runLogin(ctx, call)
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-call.done:
+		return call.token, call.err
+	case <-ctx.Done():
+		return "", fmt.Errorf("menunggu login ke hub %s dibatalkan: %w", c.Hub, ctx.Err())
 	}
 }
 
-func (c *APIClient) Login() error {
+func (c *APIClient) runLogin(ctx context.Context, call *loginCall) {
+	loginCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), c.loginTimeout())
+	defer cancel()
+
+	call.token, call.err = c.loginThroughBreaker(loginCtx)
+
 	c.mu.Lock()
-	defer c.mu.Unlock()
+	if call.err == nil {
+		c.Token = call.token
+		c.tokenExpiry = parseTokenExpiry(call.token)
+		slog.Info("Berhasil login dan memperbarui token.", "hub", c.Hub, "expires_at", c.tokenExpiry)
+	}
+	c.loginInflight = nil
+	c.mu.Unlock()
+	close(call.done)
+}
+
+func (c *APIClient) loginTimeout() time.Duration {
+	if c.HTTPClient.Timeout > 0 {
+		return c.HTTPClient.Timeout
+	}
+	return 30 * time.Second
+}
+
+// loginThroughBreaker menjalankan doLogin melalui circuit breaker hub, sehingga
+// login ke hub yang tidak dapat dijangkau ikut ditolak dan kegagalannya dihitung.
+func (c *APIClient) loginThroughBreaker(ctx context.Context) (string, error) {
+	hub := breakerKey(c.BaseURL)
+	if err := c.breaker.Allow(hub); err != nil {
+		return "", err
+	}
+	token, err := c.doLogin(ctx)
+	switch {
+	case err == nil:
+		c.breaker.Success(hub)
+	case isRetryable(ctx, err):
+		c.breaker.Failure(hub)
+	case ctx.Err() != nil:
+		c.breaker.Abort(hub)
+	default:
+		// Kredensial ditolak (4xx) berarti hub merespons.
+		c.breaker.Success(hub)
+	}
+	return token, err
+}
 
-	slog.Info("Mencoba login untuk mendapatkan token baru...", "url", c.BaseURL)
+func (c *APIClient) doLogin(ctx context.Context) (string, error) {
+	slog.Info("Mencoba login untuk mendapatkan token baru...", "hub", c.Hub, "url", c.BaseURL)
 	loginURL := fmt.Sprintf("%s/api/v1/auth/login", c.BaseURL)
 
 	payload := LoginRequest{Email: c.Email, Password: c.Password}
 	payloadBytes, err := json.Marshal(payload)
 	if err != nil {
-		return fmt.Errorf("gagal marshal payload login: %w", err)
+		return "", fmt.Errorf("gagal marshal payload login: %w", err)
 	}
 
-	req, err := http.NewRequest("POST", loginURL, bytes.NewBuffer(payloadBytes))
+	req, err := http.NewRequestWithContext(ctx, "POST", loginURL, bytes.NewBuffer(payloadBytes))
 	if err != nil {
-		return fmt.Errorf("gagal membuat request login: %w", err)
+		return "", fmt.Errorf("gagal membuat request login: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
 
 	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("gagal melakukan request login: %w", err)
+		return "", fmt.Errorf("gagal melakukan request login: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		bodyBytes, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("login gagal dengan status code %d: %s", resp.StatusCode, string(bodyBytes))
+		return "", fmt.Errorf("login gagal: %w", &StatusError{StatusCode: resp.StatusCode, URL: loginURL, Body: string(bodyBytes)})
 	}
 
 	var loginResp LoginResponse
 	if err := json.NewDecoder(resp.Body).Decode(&loginResp); err != nil {
-		return fmt.Errorf("gagal decode response login: %w", err)
+		return "", fmt.Errorf("gagal decode response login: %w", err)
 	}
 
 	if !loginResp.Status || loginResp.Data.Token == "" {
-		return fmt.Errorf("login ke API tidak berhasil: %s", loginResp.Message)
+		return "", fmt.Errorf("login ke API tidak berhasil: %s", loginResp.Message)
 	}
+	return loginResp.Data.Token, nil
+}
 
-	c.Token = loginResp.Data.Token
-	slog.Info("Berhasil login dan memperbarui token.")
-	return nil
+func (c *APIClient) GetWithAuth(ctx context.Context, url string, target interface{}) error {
+	body, err := c.getBodyWithAuth(ctx, url)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, target)
 }
 
-func (c *APIClient) GetWithAuth(url string, target interface{}) error {
-	err := c.doGetRequest(url, target)
+// getCached seperti GetWithAuth, tetapi memakai cache dengan TTL milik endpoint.
+// key mengidentifikasi data yang diminta dan boleh berbeda dari url, misalnya
+// tanpa parameter rentang waktu yang selalu berubah.
+func (c *APIClient) getCached(ctx context.Context, endpoint, key, url string, target interface{}) error {
+	body, fetchedAt, err := c.cache.get(ctx, endpoint, key, isForceRefresh(ctx), func(fetchCtx context.Context) ([]byte, error) {
+		return c.getBodyWithAuth(fetchCtx, url)
+	})
+	if err != nil {
+		return err
+	}
+	recordFetch(ctx, fetchedAt)
+	return json.Unmarshal(body, target)
+}
 
-	if err != nil && strings.Contains(err.Error(), "status code tidak OK: 401") {
-		slog.Warn("Menerima status 401 (Unauthorized), mencoba login ulang untuk refresh token...")
+func (c *APIClient) getBodyWithAuth(ctx context.Context, url string) ([]byte, error) {
+	token, err := c.currentToken(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mendapatkan token API: %w", err)
+	}
 
-		if loginErr := c.Login(); loginErr != nil {
-			return fmt.Errorf("gagal refresh token setelah error 401: %w", loginErr)
+	body, err := c.getWithRetry(ctx, url, token)
+	if IsUnauthorized(err) {
+		slog.Warn("Menerima status 401 (Unauthorized), mencoba login ulang untuk refresh token...", "hub", c.Hub)
+
+		token, err = c.refreshToken(ctx, token)
+		if err != nil {
+			return nil, fmt.Errorf("gagal refresh token setelah error 401: %w", err)
 		}
 
 		slog.Info("Mencoba ulang permintaan dengan token baru...", "url", url)
-		err = c.doGetRequest(url, target)
+		body, err = c.getWithRetry(ctx, url, token)
 	}
 
-	return err
+	return body, err
 }
 
-func (c *APIClient) doGetRequest(url string, target interface{}) error {
-	if c.Token == "" {
-		return fmt.Errorf("token otentikasi kosong, silakan login terlebih dahulu")
+// getWithRetry menjalankan doGetRequest melalui circuit breaker hub klien dan
+// mengulang request yang gagal karena jaringan, timeout, atau 5xx.
+func (c *APIClient) getWithRetry(ctx context.Context, url, token string) ([]byte, error) {
+	hub := breakerKey(c.BaseURL)
+	var body []byte
+	var err error
+	for attempt := 0; attempt <= c.maxRetries; attempt++ {
+		if attempt > 0 {
+			delay := c.backoff * time.Duration(1<<(attempt-1))
+			slog.Warn("Request API gagal, mencoba ulang", "url", url, "attempt", attempt, "delay", delay, "error", err)
+			select {
+			case <-ctx.Done():
+				return nil, fmt.Errorf("request ke %s dibatalkan: %w", url, ctx.Err())
+			case <-time.After(delay):
+			}
+		}
+
+		if breakerErr := c.breaker.Allow(hub); breakerErr != nil {
+			return nil, breakerErr
+		}
+
+		body, err = c.doGetRequest(ctx, url, token)
+		if err == nil {
+			c.breaker.Success(hub)
+			return body, nil
+		}
+		if !isRetryable(ctx, err) {
+			if ctx.Err() != nil {
+				c.breaker.Abort(hub)
+			} else {
+				// Error 4xx berarti hub merespons, jadi tidak dihitung sebagai kegagalan hub.
+				c.breaker.Success(hub)
+			}
+			return nil, err
+		}
+		c.breaker.Failure(hub)
+		if ctx.Err() != nil {
+			return nil, err
+		}
 	}
+	return nil, err
+}
 
-	req, err := http.NewRequest("GET", url, nil)
+// isRetryable menandakan error berasal dari jaringan/timeout atau respons 5xx.
+func isRetryable(ctx context.Context, err error) bool {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode >= 500
+	}
+	if errors.Is(err, context.Canceled) && ctx.Err() != nil {
+		return false
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	return errors.Is(err, context.DeadlineExceeded) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+func (c *APIClient) doGetRequest(ctx context.Context, url, token string) ([]byte, error) {
+	if token == "" {
+		return nil, fmt.Errorf("token otentikasi kosong, silakan login terlebih dahulu")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
-		return fmt.Errorf("gagal membuat request GET: %w", err)
+		return nil, fmt.Errorf("gagal membuat request GET: %w", err)
 	}
-	req.Header.Set("Authorization", "Bearer "+c.Token)
+	req.Header.Set("Authorization", "Bearer "+token)
 	req.Header.Set("Content-Type", "application/json")
 
 	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("gagal melakukan request GET ke %s: %w", url, err)
+		return nil, fmt.Errorf("gagal melakukan request GET ke %s: %w", url, err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		bodyBytes, readErr := io.ReadAll(resp.Body)
 		if readErr != nil {
-			return fmt.Errorf("menerima status code tidak OK: %d dari %s (dan gagal membaca body response: %v)", resp.StatusCode, url, readErr)
+			return nil, &StatusError{StatusCode: resp.StatusCode, URL: url, Body: fmt.Sprintf("(gagal membaca body response: %v)", readErr)}
 		}
-		return fmt.Errorf("menerima status code tidak OK: %d dari %s. Response: %s", resp.StatusCode, url, string(bodyBytes))
+		return nil, &StatusError{StatusCode: resp.StatusCode, URL: url, Body: string(bodyBytes)}
 	}
 
-	return json.NewDecoder(resp.Body).Decode(target)
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("gagal membaca response dari %s: %w", url, err)
+	}
+	return body, nil
 }
@@ -1,37 +1,47 @@
 package api
 
 import (
+	"context"
 	"fmt"
 	"net/url"
 	"time"
 )
 
-func GetIpcnStatus(client *APIClient, baseURL string) (*IpcnStatusResponse, error) {
+func GetIpcnStatus(ctx context.Context, client *APIClient) (*IpcnStatusResponse, error) {
 	var target IpcnStatusResponse
-	err := client.GetWithAuth(fmt.Sprintf("%s/api/v1/ipcn/status", baseURL), &target)
+	fullURL := fmt.Sprintf("%s/api/v1/ipcn/status", client.BaseURL)
+	err := client.getCached(ctx, EndpointIpcnStatus, fullURL, fullURL, &target)
 	if err != nil {
 		return nil, err
 	}
 	return &target, nil
 }
 
-func GetIptxTraffic(client *APIClient, baseURL, sdate, edate, avg, gateway string) (*LnmIptxTrafficResponse, error) {
+// GetIptxTraffic mengambil traffic IP transit selama window terakhir. sdate/edate
+// dibentuk di sini dan selalu bergeser, jadi kunci cache memakai panjang window.
+func GetIptxTraffic(ctx context.Context, client *APIClient, window time.Duration, avg, gateway string) (*LnmIptxTrafficResponse, error) {
 	var target LnmIptxTrafficResponse
+	endDate := time.Now()
+	startDate := endDate.Add(-window)
+
+	const layout = "2006-01-02-15-04-05"
+
 	params := url.Values{}
-	params.Add("sdate", sdate)
-	params.Add("edate", edate)
+	params.Add("sdate", startDate.Format(layout))
+	params.Add("edate", endDate.Format(layout))
 	params.Add("avg", avg)
 	params.Add("gateway", gateway)
 
-	fullURL := fmt.Sprintf("%s/api/v1/lnm/prtg-data/iptx-traffic?%s", baseURL, params.Encode())
-	err := client.GetWithAuth(fullURL, &target)
+	fullURL := fmt.Sprintf("%s/api/v1/lnm/prtg-data/iptx-traffic?%s", client.BaseURL, params.Encode())
+	cacheKey := fmt.Sprintf("%s/api/v1/lnm/prtg-data/iptx-traffic?avg=%s&gateway=%s&window=%s", client.BaseURL, avg, gateway, window)
+	err := client.getCached(ctx, EndpointIptxTraffic, cacheKey, fullURL, &target)
 	if err != nil {
 		return nil, err
 	}
 	return &target, nil
 }
 
-func GetOnlineUT(client *APIClient, baseURL string) (*ToaRangeIntervalResponse, error) {
+func GetOnlineUT(ctx context.Context, client *APIClient) (*ToaRangeIntervalResponse, error) {
 	var target ToaRangeIntervalResponse
 	endDate := time.Now().UTC()
 	startDate := endDate.Add(-1 * time.Hour)
@@ -43,57 +53,61 @@ func GetOnlineUT(client *APIClient, baseURL string) (*ToaRangeIntervalResponse,
 	params.Add("end_date", endDate.Format(layout))
 	params.Add("interval", "60")
 
-	fullURL := fmt.Sprintf("%s/api/v1/toa/range-interval?%s", baseURL, params.Encode())
-	err := client.GetWithAuth(fullURL, &target)
+	fullURL := fmt.Sprintf("%s/api/v1/toa/range-interval?%s", client.BaseURL, params.Encode())
+	err := client.getCached(ctx, EndpointOnlineUT, client.BaseURL+"/api/v1/toa/range-interval", fullURL, &target)
 	if err != nil {
 		return nil, err
 	}
 	return &target, nil
 }
 
-func GetIpcnSensorStatus(client *APIClient, baseURL string, deviceName string) (*IpcnSensorStatusResponse, error) {
+func GetIpcnSensorStatus(ctx context.Context, client *APIClient, deviceName string) (*IpcnSensorStatusResponse, error) {
 	var target IpcnSensorStatusResponse
-	fullURL := fmt.Sprintf("%s/api/v1/ipcn/sensor-status", baseURL)
+	fullURL := fmt.Sprintf("%s/api/v1/ipcn/sensor-status", client.BaseURL)
 	if deviceName != "" {
 		fullURL += "?device_name=" + url.QueryEscape(deviceName)
 	}
-	err := client.GetWithAuth(fullURL, &target)
+	err := client.getCached(ctx, EndpointIpcnSensorStatus, fullURL, fullURL, &target)
 	if err != nil {
 		return nil, err
 	}
 	return &target, nil
 }
 
-func GetDevicePropertiesStatus(client *APIClient, baseURL string) (*DevicePropertiesStatusResponse, error) {
+func GetDevicePropertiesStatus(ctx context.Context, client *APIClient) (*DevicePropertiesStatusResponse, error) {
 	var target DevicePropertiesStatusResponse
-	err := client.GetWithAuth(fmt.Sprintf("%s/api/v1/device_properties/status", baseURL), &target)
+	fullURL := fmt.Sprintf("%s/api/v1/device_properties/status", client.BaseURL)
+	err := client.getCached(ctx, EndpointDeviceProperties, fullURL, fullURL, &target)
 	if err != nil {
 		return nil, err
 	}
 	return &target, nil
 }
 
-func GetCnBeacon(client *APIClient, baseURL string) (*CnBeaconResponse, error) {
+func GetCnBeacon(ctx context.Context, client *APIClient) (*CnBeaconResponse, error) {
 	var target CnBeaconResponse
-	err := client.GetWithAuth(fmt.Sprintf("%s/api/v1/lnm/cn_beacon", baseURL), &target)
+	fullURL := fmt.Sprintf("%s/api/v1/lnm/cn_beacon", client.BaseURL)
+	err := client.getCached(ctx, EndpointCnBeacon, fullURL, fullURL, &target)
 	if err != nil {
 		return nil, err
 	}
 	return &target, nil
 }
 
-func GetBeamTerminalStatus(client *APIClient, baseURL string) (*TerminalBeamStatusResponse, error) {
+func GetBeamTerminalStatus(ctx context.Context, client *APIClient) (*TerminalBeamStatusResponse, error) {
 	var target TerminalBeamStatusResponse
-	err := client.GetWithAuth(fmt.Sprintf("%s/api/v1/terminal/beam-terminal-status", baseURL), &target)
+	fullURL := fmt.Sprintf("%s/api/v1/terminal/beam-terminal-status", client.BaseURL)
+	err := client.getCached(ctx, EndpointBeamStatus, fullURL, fullURL, &target)
 	if err != nil {
 		return nil, err
 	}
 	return &target, nil
 }
 
-func GetTerminalStatusTotalIntegrated(client *APIClient, baseURL string) (*TerminalStatusTotalIntegratedResponse, error) {
+func GetTerminalStatusTotalIntegrated(ctx context.Context, client *APIClient) (*TerminalStatusTotalIntegratedResponse, error) {
 	var target TerminalStatusTotalIntegratedResponse
-	err := client.GetWithAuth(fmt.Sprintf("%s/api/v1/terminal/status/total/integrated", baseURL), &target)
+	fullURL := fmt.Sprintf("%s/api/v1/terminal/status/total/integrated", client.BaseURL)
+	err := client.getCached(ctx, EndpointTerminalStatus, fullURL, fullURL, &target)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,103 @@
+package api
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+)
+
+// HubConfig adalah koneksi ke API satu hub G1x.
+type HubConfig struct {
+	Name               string
+	BaseURL            string
+	Email              string
+	Password           string
+	InsecureSkipVerify bool
+	CAFile             string
+}
+
+// Registry menyimpan satu APIClient per hub, masing-masing dengan URL,
+// kredensial, token, cache, dan circuit breaker sendiri.
+type Registry struct {
+	clients map[string]*APIClient
+	hubs    []string
+}
+
+// NewRegistry membuat klien untuk setiap hub. opts dipakai bersama, kecuali
+// pengaturan TLS yang dibangun dari HubConfig masing-masing.
+func NewRegistry(hubs []HubConfig, opts ClientOptions) (*Registry, error) {
+	registry := &Registry{clients: make(map[string]*APIClient, len(hubs))}
+	for _, hub := range hubs {
+		if _, exists := registry.clients[hub.Name]; exists {
+			return nil, fmt.Errorf("hub %s terdaftar lebih dari sekali", hub.Name)
+		}
+		tlsConfig, err := hubTLSConfig(hub)
+		if err != nil {
+			return nil, err
+		}
+		hubOpts := opts
+		hubOpts.TLS = tlsConfig
+
+		client := NewAPIClient(hub.BaseURL, hub.Email, hub.Password, hubOpts)
+		client.Hub = hub.Name
+		registry.clients[hub.Name] = client
+		registry.hubs = append(registry.hubs, hub.Name)
+	}
+	return registry, nil
+}
+
+func hubTLSConfig(hub HubConfig) (*tls.Config, error) {
+	if !hub.InsecureSkipVerify && hub.CAFile == "" {
+		return nil, nil
+	}
+	tlsConfig := &tls.Config{InsecureSkipVerify: hub.InsecureSkipVerify}
+	if hub.CAFile != "" {
+		pem, err := os.ReadFile(hub.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("gagal membaca CA hub %s dari %s: %w", hub.Name, hub.CAFile, err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("file CA hub %s (%s) tidak berisi sertifikat PEM yang valid", hub.Name, hub.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	return tlsConfig, nil
+}
+
+// Client mengembalikan klien untuk hub, atau error jika hub tidak terdaftar.
+func (r *Registry) Client(hub string) (*APIClient, error) {
+	client, ok := r.clients[hub]
+	if !ok {
+		return nil, fmt.Errorf("hub %s tidak terdaftar di registry API", hub)
+	}
+	return client, nil
+}
+
+// Hubs mengembalikan nama hub sesuai urutan pendaftaran.
+func (r *Registry) Hubs() []string {
+	return r.hubs
+}
+
+// LoginAll login ke semua hub secara paralel. Kegagalan hanya dicatat karena
+// klien akan login ulang otomatis pada request berikutnya.
+func (r *Registry) LoginAll(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, hub := range r.hubs {
+		wg.Add(1)
+		go func(client *APIClient) {
+			defer wg.Done()
+			if err := client.Login(ctx); err != nil {
+				slog.Error("Login awal ke API hub gagal.", "hub", client.Hub, "error", err)
+			}
+		}(r.clients[hub])
+	}
+	wg.Wait()
+}
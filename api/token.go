@@ -0,0 +1,35 @@
+package api
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// tokenRefreshMargin adalah jarak sebelum exp token saat token diperbarui.
+const tokenRefreshMargin = 2 * time.Minute
+
+// parseTokenExpiry membaca klaim exp dari payload JWT tanpa memverifikasi
+// signature. Mengembalikan time.Time kosong jika token bukan JWT atau tanpa exp.
+func parseTokenExpiry(token string) time.Time {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return time.Time{}
+	}
+	var claims struct {
+		Exp json.Number `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Exp == "" {
+		return time.Time{}
+	}
+	exp, err := claims.Exp.Float64()
+	if err != nil || exp <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(int64(exp), 0)
+}
@@ -0,0 +1,106 @@
+package bot
+
+import (
+	config "bella/config"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultQueryRange = time.Hour
+	maxQueryRange     = 7 * 24 * time.Hour
+)
+
+// gatewayAliases memetakan kode, nama, dan hub IPCN gateway (huruf kecil,
+// mis. "jyp", "jayapura", "g1g") ke nama gateway. Dibangun dari botGateways.
+var gatewayAliases = buildGatewayAliases()
+
+func buildGatewayAliases() map[string]string {
+	aliases := make(map[string]string)
+	for _, gw := range botGateways {
+		aliases[gw.Code] = gw.Gateway()
+		aliases[strings.ToLower(gw.Name)] = gw.Gateway()
+		if hub := config.HubForGateway(gw.Name); hub != "" {
+			aliases[strings.ToLower(hub)] = gw.Gateway()
+		}
+	}
+	return aliases
+}
+
+// resolveGateway menerima kode (jyp/mnk/tmk) atau nama gateway dan
+// mengembalikan nama gateway dalam huruf besar seperti yang dipakai service.
+func resolveGateway(arg string) (string, bool) {
+	gateway, ok := gatewayAliases[strings.ToLower(strings.TrimSpace(arg))]
+	return gateway, ok
+}
+
+// unknownGatewayMessage adalah balasan untuk argumen gateway yang tidak dikenal,
+// mis. "Gateway 'abc' tidak dikenal. Gunakan jyp, mnk, atau tmk."
+func unknownGatewayMessage(arg string) string {
+	codes := make([]string, len(botGateways))
+	for i, gw := range botGateways {
+		codes[i] = gw.Code
+	}
+	var list string
+	switch n := len(codes); n {
+	case 1:
+		list = codes[0]
+	case 2:
+		list = codes[0] + " atau " + codes[1]
+	default:
+		list = strings.Join(codes[:n-1], ", ") + ", atau " + codes[n-1]
+	}
+	return fmt.Sprintf("Gateway '%s' tidak dikenal. Gunakan %s.", arg, list)
+}
+
+var rangeArgPattern = regexp.MustCompile(`^[0-9]+[smhd]$`)
+
+// isRangeArg menandakan argumen berbentuk range (mis. "30m", "6h", "7d").
+func isRangeArg(arg string) bool {
+	return rangeArgPattern.MatchString(strings.ToLower(strings.TrimSpace(arg)))
+}
+
+// parseRange mengubah argumen seperti "30m", "6h" atau "7d" menjadi durasi.
+func parseRange(arg string) (time.Duration, error) {
+	arg = strings.ToLower(strings.TrimSpace(arg))
+	if arg == "" {
+		return defaultQueryRange, nil
+	}
+
+	var duration time.Duration
+	if strings.HasSuffix(arg, "d") {
+		days, err := strconv.Atoi(strings.TrimSuffix(arg, "d"))
+		if err != nil {
+			return 0, fmt.Errorf("range tidak valid: %s", arg)
+		}
+		duration = time.Duration(days) * 24 * time.Hour
+	} else {
+		parsed, err := time.ParseDuration(arg)
+		if err != nil {
+			return 0, fmt.Errorf("range tidak valid: %s", arg)
+		}
+		duration = parsed
+	}
+
+	if duration <= 0 {
+		return 0, fmt.Errorf("range harus lebih dari 0: %s", arg)
+	}
+	if duration > maxQueryRange {
+		return 0, fmt.Errorf("range maksimal adalah 7d")
+	}
+	return duration, nil
+}
+
+// formatRange menampilkan durasi range dalam bentuk ringkas (mis. "6h" atau "7d").
+func formatRange(duration time.Duration) string {
+	if duration >= 24*time.Hour && duration%(24*time.Hour) == 0 {
+		return fmt.Sprintf("%dd", duration/(24*time.Hour))
+	}
+	if duration%time.Hour == 0 {
+		return fmt.Sprintf("%dh", duration/time.Hour)
+	}
+	return fmt.Sprintf("%dm", duration/time.Minute)
+}
@@ -3,8 +3,14 @@ package bot
 import (
 	"bella/api"
 	config "bella/config"
+	"bella/internal/history"
+	"bella/internal/moddemod"
+	"bella/internal/prtgn"
+	"bella/internal/satnet"
+	"bella/internal/state"
 	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"log/slog"
@@ -25,9 +31,21 @@ const (
 )
 
 type CommandHandler struct {
-	bot       *tgbotapi.BotAPI
-	config    *config.AppConfig
-	apiClient *api.APIClient
+	bot        *tgbotapi.BotAPI
+	config     *config.AppConfig
+	apiClients *api.Registry
+	repos      Repositories
+	state      *state.Manager
+	commands   *CommandRegistry
+}
+
+// Repositories berisi akses database per gateway (JAYAPURA, MANOKWARI, TIMIKA)
+// yang dipakai perintah bot.
+type Repositories struct {
+	Satnet   map[string]satnet.Repository
+	ModDemod map[string]moddemod.Repository
+	History  *history.Store
+	PRTG     prtgn.PRTGAPIInterface
 }
 
 type GatewayData struct {
@@ -39,14 +57,25 @@ type GatewayData struct {
 	CnBeacon         *api.CnBeaconResponse
 	BeamStatus       *api.TerminalBeamStatusResponse
 	IntegratedStatus *api.TerminalStatusTotalIntegratedResponse
+
+	// UnreachableHubs berisi hub yang circuit breaker-nya terbuka saat data diambil.
+	UnreachableHubs []string
+	// FetchedAt adalah waktu pengambilan data tertua; bisa lebih lama dari
+	// waktu perintah jika data diambil dari cache.
+	FetchedAt time.Time
 }
 
-func NewCommandHandler(bot *tgbotapi.BotAPI, config *config.AppConfig, apiClient *api.APIClient) *CommandHandler {
-	return &CommandHandler{
-		bot:       bot,
-		config:    config,
-		apiClient: apiClient,
+func NewCommandHandler(bot *tgbotapi.BotAPI, config *config.AppConfig, apiClients *api.Registry, repos Repositories, stateMgr *state.Manager) *CommandHandler {
+	ch := &CommandHandler{
+		bot:        bot,
+		config:     config,
+		apiClients: apiClients,
+		repos:      repos,
+		state:      stateMgr,
+		commands:   NewCommandRegistry(),
 	}
+	registerCommands(ch.commands, ch)
+	return ch
 }
 
 func (ch *CommandHandler) sendMessage(chatID int64, text string) {
@@ -61,34 +90,6 @@ func (ch *CommandHandler) sendMessage(chatID int64, text string) {
 	}
 }
 
-// getPublicCommands mendefinisikan perintah untuk pengguna biasa.
-func getPublicCommands() []tgbotapi.BotCommand {
-	return []tgbotapi.BotCommand{
-		{Command: "start", Description: "Memulai interaksi dengan bot"},
-		{Command: "help", Description: "Menampilkan pesan bantuan ini"},
-		{Command: "myid", Description: "Menampilkan ID Telegram Anda"},
-	}
-}
-
-// getAdminCommands mendefinisikan semua perintah untuk pengguna admin.
-func getAdminCommands() []tgbotapi.BotCommand {
-	return []tgbotapi.BotCommand{
-		{Command: "help", Description: "Tampilkan bantuan"},
-		{Command: "myid", Description: "Tampilkan ID Telegram Anda"},
-		{Command: "satria1_gateway_all", Description: "Ringkasan status semua Gateway"},
-		{Command: "satria1_gateway_jyp", Description: "Ringkasan status Gateway Jayapura"},
-		{Command: "satria1_gateway_mnk", Description: "Ringkasan status Gateway Manokwari"},
-		{Command: "satria1_gateway_tmk", Description: "Ringkasan status Gateway Timika"},
-		{Command: "satria1_iptx_jyp", Description: "Info IP Transit Gateway Jayapura"},
-		{Command: "satria1_iptx_mnk", Description: "Info IP Transit Gateway Manokwari"},
-		{Command: "satria1_iptx_tmk", Description: "Info IP Transit Gateway Timika"},
-		{Command: "log_error", Description: "Tampilkan log error terakhir"},
-		{Command: "log_notif", Description: "Tampilkan log notifikasi terakhir"},
-		{Command: "log_alerts_active", Description: "Tampilkan alert yang sedang aktif"},
-		{Command: "log_all", Description: "Tampilkan semua log terakhir"},
-	}
-}
-
 func (ch *CommandHandler) sendFile(chatID int64, title, content, fileName string) {
 	// Buat file sementara
 	tmpFile, err := os.Create(fileName)
@@ -221,26 +222,19 @@ func (ch *CommandHandler) readActiveAlerts() string {
 	return prettyJSON.String()
 }
 
-func (ch *CommandHandler) fetchGatewayData(gwName string) GatewayData {
+func (ch *CommandHandler) fetchGatewayData(gwName string, forceRefresh bool) GatewayData {
 	var wg sync.WaitGroup
 	var data GatewayData
 
-	var g1gURL, g1kURL, g1lURL, sensorURL string
-	switch gwName {
-	case "Jayapura":
-		g1gURL = ch.config.G1gURL
-		g1kURL = ch.config.G1kURL
-		sensorURL = g1gURL
-	case "Manokwari":
-		g1kURL = ch.config.G1kURL
-		sensorURL = g1kURL
-	case "Timika":
-		g1lURL = ch.config.G1lURL
-		g1kURL = ch.config.G1kURL
-		sensorURL = g1lURL
+	ipcnClient, centralClient, err := ch.gatewayClients(gwName)
+	if err != nil {
+		slog.Error("Klien API untuk gateway tidak tersedia", "gateway", gwName, "error", err)
+		return data
 	}
 
-	ipcnStatusURL := sensorURL
+	ctx, cancel := context.WithTimeout(context.Background(), ch.apiDeadline())
+	defer cancel()
+	ctx, fetchInfo := apiContext(ctx, forceRefresh)
 
 	logApiError := func(taskName string, err error) {
 		if err != nil {
@@ -252,51 +246,49 @@ func (ch *CommandHandler) fetchGatewayData(gwName string) GatewayData {
 		func() {
 			defer wg.Done()
 			var err error
-			data.IpcnStatus, err = api.GetIpcnStatus(ch.apiClient, ipcnStatusURL)
+			data.IpcnStatus, err = api.GetIpcnStatus(ctx, ipcnClient)
 			logApiError("IpcnStatus", err)
 		},
 		func() {
 			defer wg.Done()
 			var err error
-			sdate := time.Now().Add(-5 * time.Minute).Format("2006-01-02-15-04-05")
-			edate := time.Now().Format("2006-01-02-15-04-05")
-			data.IptxTraffic, err = api.GetIptxTraffic(ch.apiClient, ipcnStatusURL, sdate, edate, "300", strings.ToLower(gwName))
+			data.IptxTraffic, err = api.GetIptxTraffic(ctx, ipcnClient, 5*time.Minute, "300", strings.ToLower(gwName))
 			logApiError("IptxTraffic", err)
 		},
 		func() {
 			defer wg.Done()
 			var err error
-			data.OnlineUT, err = api.GetOnlineUT(ch.apiClient, g1kURL)
+			data.OnlineUT, err = api.GetOnlineUT(ctx, centralClient)
 			logApiError("OnlineUT", err)
 		},
 		func() {
 			defer wg.Done()
 			var err error
-			data.IpcnSensors, err = api.GetIpcnSensorStatus(ch.apiClient, sensorURL, "")
+			data.IpcnSensors, err = api.GetIpcnSensorStatus(ctx, ipcnClient, "")
 			logApiError("IpcnSensorStatus", err)
 		},
 		func() {
 			defer wg.Done()
 			var err error
-			data.DeviceProps, err = api.GetDevicePropertiesStatus(ch.apiClient, g1kURL)
+			data.DeviceProps, err = api.GetDevicePropertiesStatus(ctx, centralClient)
 			logApiError("DevicePropertiesStatus", err)
 		},
 		func() {
 			defer wg.Done()
 			var err error
-			data.CnBeacon, err = api.GetCnBeacon(ch.apiClient, g1kURL)
+			data.CnBeacon, err = api.GetCnBeacon(ctx, centralClient)
 			logApiError("CnBeacon", err)
 		},
 		func() {
 			defer wg.Done()
 			var err error
-			data.BeamStatus, err = api.GetBeamTerminalStatus(ch.apiClient, g1kURL)
+			data.BeamStatus, err = api.GetBeamTerminalStatus(ctx, centralClient)
 			logApiError("BeamTerminalStatus", err)
 		},
 		func() {
 			defer wg.Done()
 			var err error
-			data.IntegratedStatus, err = api.GetTerminalStatusTotalIntegrated(ch.apiClient, g1kURL)
+			data.IntegratedStatus, err = api.GetTerminalStatusTotalIntegrated(ctx, centralClient)
 			logApiError("TerminalStatusTotalIntegrated", err)
 		},
 	}
@@ -306,14 +298,68 @@ func (ch *CommandHandler) fetchGatewayData(gwName string) GatewayData {
 		go task()
 	}
 	wg.Wait()
+
+	data.UnreachableHubs = unreachableHubs(ipcnClient, centralClient)
+	data.FetchedAt = fetchInfo.Oldest()
 	return data
 }
 
-func (ch *CommandHandler) HandleGatewaySummary(chatID int64, gwName string) {
-	slog.Info("Menangani perintah ringkasan gateway", "gateway", gwName)
+// apiContext memasang pencatat umur data dan, jika diminta, melewati cache API.
+func apiContext(ctx context.Context, forceRefresh bool) (context.Context, *api.FetchInfo) {
+	if forceRefresh {
+		ctx = api.WithForceRefresh(ctx)
+	}
+	return api.WithFetchInfo(ctx)
+}
+
+// isRefreshArg menandakan argumen perintah meminta data baru tanpa cache.
+func isRefreshArg(args string) bool {
+	arg := strings.ToLower(strings.TrimSpace(args))
+	return arg == "refresh" || arg == "fresh"
+}
+
+// apiDeadline adalah batas waktu total satu perintah bot ke API G1x,
+// cukup untuk semua retry dengan backoff.
+func (ch *CommandHandler) apiDeadline() time.Duration {
+	retries := time.Duration(ch.config.APIRetryMax)
+	return ch.config.APITimeout*(retries+1) + ch.config.APIRetryBackoff*(1<<retries)
+}
+
+// gatewayClients mengembalikan klien hub IPCN gateway dan klien hub pusat.
+func (ch *CommandHandler) gatewayClients(gwName string) (*api.APIClient, *api.APIClient, error) {
+	ipcnClient, err := ch.apiClients.Client(config.HubForGateway(gwName))
+	if err != nil {
+		return nil, nil, err
+	}
+	centralClient, err := ch.apiClients.Client(config.CentralHub)
+	if err != nil {
+		return nil, nil, err
+	}
+	return ipcnClient, centralClient, nil
+}
+
+// unreachableHubs mengembalikan nama hub (G1G/G1K/G1L) yang circuit breaker-nya terbuka.
+func unreachableHubs(clients ...*api.APIClient) []string {
+	var hubs []string
+	seen := make(map[string]bool)
+	for _, client := range clients {
+		if seen[client.Hub] {
+			continue
+		}
+		seen[client.Hub] = true
+		if client.IsHubUnreachable() {
+			hubs = append(hubs, client.Hub)
+		}
+	}
+	return hubs
+}
+
+func (ch *CommandHandler) HandleGatewaySummary(chatID int64, gwName, args string) {
+	forceRefresh := isRefreshArg(args)
+	slog.Info("Menangani perintah ringkasan gateway", "gateway", gwName, "refresh", forceRefresh)
 	ch.sendMessage(chatID, escape(fmt.Sprintf("Mengambil data untuk Gateway %s, mohon tunggu...", gwName)))
 
-	data := ch.fetchGatewayData(gwName)
+	data := ch.fetchGatewayData(gwName, forceRefresh)
 
 	jsonBytes, _ := json.MarshalIndent(data.IpcnSensors, "", "  ")
 	slog.Debug("Data Sensor IPCN yang diterima dari API", "gateway", gwName, "data", string(jsonBytes))
@@ -322,20 +368,24 @@ func (ch *CommandHandler) HandleGatewaySummary(chatID int64, gwName string) {
 	ch.sendMessage(chatID, response)
 }
 
-func (ch *CommandHandler) HandleGatewayAll(chatID int64) {
-	slog.Info("Menangani perintah ringkasan semua gateway")
+func (ch *CommandHandler) HandleGatewayAll(chatID int64, args string) {
+	forceRefresh := isRefreshArg(args)
+	slog.Info("Menangani perintah ringkasan semua gateway", "refresh", forceRefresh)
 	ch.sendMessage(chatID, escape("Mengambil data untuk semua gateway, ini mungkin memakan waktu beberapa saat..."))
 
 	var wg sync.WaitGroup
 	allData := make(map[string]GatewayData)
 	mu := &sync.Mutex{}
-	gateways := []string{"Jayapura", "Manokwari", "Timika"}
+	var gateways []string
+	for _, gw := range botGateways {
+		gateways = append(gateways, gw.Name)
+	}
 
 	wg.Add(len(gateways))
 	for _, gw := range gateways {
 		go func(gwName string) {
 			defer wg.Done()
-			data := ch.fetchGatewayData(gwName)
+			data := ch.fetchGatewayData(gwName, forceRefresh)
 			mu.Lock()
 			allData[gwName] = data
 			mu.Unlock()
@@ -352,6 +402,8 @@ func (ch *CommandHandler) HandleGatewayAll(chatID int64) {
 		}
 
 		finalReport.WriteString(FormatGatewayHeader(gwName))
+		finalReport.WriteString(formatDataAge(data.FetchedAt))
+		finalReport.WriteString(formatUnreachableHubs(data.UnreachableHubs))
 		finalReport.WriteString(formatSystemStatus(data))
 		finalReport.WriteString(formatTrafficInfo(data))
 		finalReport.WriteString(formatIpcnDeviceSummary(data, gwName))
@@ -367,23 +419,16 @@ func (ch *CommandHandler) HandleGatewayAll(chatID int64) {
 	ch.sendMessage(chatID, finalReport.String())
 }
 
-func (ch *CommandHandler) HandleIpTransitInfo(chatID int64, gwName string) {
-	slog.Info("Menangani perintah info IP Transit", "gateway", gwName)
+func (ch *CommandHandler) HandleIpTransitInfo(chatID int64, gwName, args string) {
+	forceRefresh := isRefreshArg(args)
+	slog.Info("Menangani perintah info IP Transit", "gateway", gwName, "refresh", forceRefresh)
 	ch.sendMessage(chatID, escape(fmt.Sprintf("Mengambil data IP Transit untuk Gateway %s...", gwName)))
 
-	var g1gURL, g1kURL, g1lURL, ipcnURL string
-	switch gwName {
-	case "Jayapura":
-		g1gURL = ch.config.G1gURL
-		g1kURL = ch.config.G1kURL
-		ipcnURL = g1gURL
-	case "Manokwari":
-		g1kURL = ch.config.G1kURL
-		ipcnURL = g1kURL
-	case "Timika":
-		g1lURL = ch.config.G1lURL
-		g1kURL = ch.config.G1kURL
-		ipcnURL = g1lURL
+	ipcnClient, centralClient, err := ch.gatewayClients(gwName)
+	if err != nil {
+		slog.Error("Klien API untuk gateway tidak tersedia", "gateway", gwName, "error", err)
+		ch.sendMessage(chatID, escape(fmt.Sprintf("❌ Hub API untuk Gateway %s tidak terkonfigurasi.", gwName)))
+		return
 	}
 
 	var status *api.IpcnStatusResponse
@@ -391,6 +436,10 @@ func (ch *CommandHandler) HandleIpTransitInfo(chatID int64, gwName string) {
 	var onlineUT *api.ToaRangeIntervalResponse
 	var wg sync.WaitGroup
 
+	ctx, cancel := context.WithTimeout(context.Background(), ch.apiDeadline())
+	defer cancel()
+	ctx, fetchInfo := apiContext(ctx, forceRefresh)
+
 	logApiError := func(taskName string, err error) {
 		if err != nil {
 			slog.Error("API call failed", "task", taskName, "gateway", gwName, "error", err)
@@ -401,25 +450,414 @@ func (ch *CommandHandler) HandleIpTransitInfo(chatID int64, gwName string) {
 	go func() {
 		defer wg.Done()
 		var err error
-		status, err = api.GetIpcnStatus(ch.apiClient, ipcnURL)
+		status, err = api.GetIpcnStatus(ctx, ipcnClient)
 		logApiError("IpcnStatus (IP Transit)", err)
 	}()
 	go func() {
 		defer wg.Done()
 		var err error
-		sdate := time.Now().Add(-5 * time.Minute).Format("2006-01-02-15-04-05")
-		edate := time.Now().Format("2006-01-02-15-04-05")
-		traffic, err = api.GetIptxTraffic(ch.apiClient, ipcnURL, sdate, edate, "300", strings.ToLower(gwName))
+		traffic, err = api.GetIptxTraffic(ctx, ipcnClient, 5*time.Minute, "300", strings.ToLower(gwName))
 		logApiError("IptxTraffic (IP Transit)", err)
 	}()
 	go func() {
 		defer wg.Done()
 		var err error
-		onlineUT, err = api.GetOnlineUT(ch.apiClient, g1kURL)
+		onlineUT, err = api.GetOnlineUT(ctx, centralClient)
 		logApiError("OnlineUT (IP Transit)", err)
 	}()
 	wg.Wait()
 
 	response := FormatIpTransitInfo(gwName, status, traffic, onlineUT)
+	response = formatUnreachableHubs(unreachableHubs(ipcnClient, centralClient)) + response
+	response = formatDataAge(fetchInfo.Oldest()) + response
 	ch.sendMessage(chatID, response)
 }
+
+// HandleSatnet menangani perintah /satnet <gateway> <nama> [range] [chart].
+func (ch *CommandHandler) HandleSatnet(chatID int64, args string) {
+	fields := strings.Fields(args)
+	if len(fields) < 2 {
+		ch.sendMessage(chatID, escape("Format: /satnet <gateway> <nama_satnet> [range] [chart]\nContoh: /satnet jyp SATNET-01 6h chart"))
+		return
+	}
+
+	gateway, ok := resolveGateway(fields[0])
+	if !ok {
+		ch.sendMessage(chatID, escape(unknownGatewayMessage(fields[0])))
+		return
+	}
+	repo, ok := ch.repos.Satnet[gateway]
+	if !ok || repo == nil {
+		ch.sendMessage(chatID, escape(fmt.Sprintf("Database satnet untuk gateway %s tidak tersedia.", gateway)))
+		return
+	}
+
+	satnetName := fields[1]
+	queryRange := defaultQueryRange
+	withChart := false
+	for _, arg := range fields[2:] {
+		if strings.EqualFold(arg, "chart") {
+			withChart = true
+			continue
+		}
+		parsed, err := parseRange(arg)
+		if err != nil {
+			ch.sendMessage(chatID, escape(err.Error()))
+			return
+		}
+		queryRange = parsed
+	}
+
+	slog.Info("Menangani perintah satnet", "gateway", gateway, "satnet", satnetName, "range", queryRange)
+
+	ctx, cancel := context.WithTimeout(context.Background(), ch.config.QueryTimeout)
+	defer cancel()
+
+	history, err := repo.GetSatnetHistory(ctx, satnetName, queryRange)
+	if err != nil {
+		slog.Error("Gagal mengambil riwayat satnet", "gateway", gateway, "satnet", satnetName, "error", err)
+		ch.sendMessage(chatID, escape("Gagal mengambil data satnet dari database."))
+		return
+	}
+	if len(history) == 0 {
+		ch.sendMessage(chatID, escape(fmt.Sprintf("Tidak ada data untuk satnet %s di gateway %s dalam %s terakhir.", satnetName, gateway, formatRange(queryRange))))
+		return
+	}
+
+	var terminalCount *satnet.TerminalCount
+	counts, err := repo.GetTerminalStatuses(ctx, []string{satnetName})
+	if err != nil {
+		slog.Warn("Gagal mengambil status terminal satnet", "gateway", gateway, "satnet", satnetName, "error", err)
+	} else if count, ok := counts[satnetName]; ok {
+		terminalCount = &count
+	}
+
+	var incident *state.ActiveAlert
+	if alert, ok := ch.state.GetAlertByKey(fmt.Sprintf("satnet_%s_%s", gateway, satnetName)); ok {
+		incident = &alert
+	}
+
+	ch.sendMessage(chatID, FormatSatnetDetail(gateway, satnetName, queryRange, history, terminalCount, incident, withChart))
+}
+
+// HandlePrtg menangani perintah /prtg <gateway> <NIF|IPTX|sensor> [range] [chart].
+func (ch *CommandHandler) HandlePrtg(chatID int64, args string) {
+	fields := strings.Fields(args)
+	if len(fields) < 2 {
+		ch.sendMessage(chatID, escape("Format: /prtg <gateway> <NIF|IPTX|sensor> [range] [chart]\nContoh: /prtg jyp IPTX 6h chart"))
+		return
+	}
+	if ch.repos.PRTG == nil {
+		ch.sendMessage(chatID, escape("Integrasi PRTG tidak dikonfigurasi."))
+		return
+	}
+
+	gateway, ok := resolveGateway(fields[0])
+	if !ok {
+		ch.sendMessage(chatID, escape(unknownGatewayMessage(fields[0])))
+		return
+	}
+
+	var queryWords []string
+	queryRange := defaultQueryRange
+	withChart := false
+	for _, arg := range fields[1:] {
+		switch {
+		case strings.EqualFold(arg, "chart"):
+			withChart = true
+		case isRangeArg(arg):
+			parsed, err := parseRange(arg)
+			if err != nil {
+				ch.sendMessage(chatID, escape(err.Error()))
+				return
+			}
+			queryRange = parsed
+		default:
+			queryWords = append(queryWords, arg)
+		}
+	}
+	query := strings.Join(queryWords, " ")
+	if query == "" {
+		ch.sendMessage(chatID, escape("Sebutkan kategori (NIF/IPTX), id, atau nama sensor."))
+		return
+	}
+
+	sensor, ok := ch.findPrtgSensor(chatID, gateway, query)
+	if !ok {
+		return
+	}
+
+	slog.Info("Menangani perintah prtg", "gateway", gateway, "sensor", sensor.ID, "range", queryRange)
+
+	ctx, cancel := context.WithTimeout(context.Background(), ch.config.PRTGTimeout*2)
+	defer cancel()
+
+	status, err := ch.repos.PRTG.GetSensorStatus(ctx, sensor)
+	if err != nil {
+		slog.Error("Gagal mengambil status sensor PRTG", "sensor", sensor.ID, "error", err)
+		ch.sendMessage(chatID, escape("Gagal mengambil status sensor dari PRTG."))
+		return
+	}
+	points, err := ch.repos.PRTG.GetSensorHistory(ctx, sensor, queryRange)
+	if err != nil {
+		slog.Warn("Gagal mengambil data historis sensor PRTG", "sensor", sensor.ID, "error", err)
+	}
+
+	ch.sendMessage(chatID, FormatPrtgDetail(status, queryRange, points, withChart))
+}
+
+// findPrtgSensor mencari tepat satu sensor katalog. Jika tidak ditemukan atau
+// ambigu, balasan sudah dikirim ke chat dan ok bernilai false.
+func (ch *CommandHandler) findPrtgSensor(chatID int64, gateway, query string) (config.PRTGSensor, bool) {
+	sensors := ch.repos.PRTG.FindSensors(gateway, query)
+	if len(sensors) == 0 {
+		ch.sendMessage(chatID, escape(fmt.Sprintf("Sensor PRTG '%s' tidak ditemukan di gateway %s.", query, gateway)))
+		return config.PRTGSensor{}, false
+	}
+	if len(sensors) > 1 {
+		ch.sendMessage(chatID, FormatPrtgSensorChoices(gateway, query, sensors))
+		return config.PRTGSensor{}, false
+	}
+	return sensors[0], true
+}
+
+// parsePrtgTarget membaca argumen <gateway> <sensor> yang dipakai perintah
+// kontrol PRTG dan mengembalikan sisa argumen.
+func (ch *CommandHandler) parsePrtgTarget(chatID int64, fields []string, usage string) (config.PRTGSensor, []string, bool) {
+	if ch.repos.PRTG == nil {
+		ch.sendMessage(chatID, escape("Integrasi PRTG tidak dikonfigurasi."))
+		return config.PRTGSensor{}, nil, false
+	}
+	if len(fields) < 2 {
+		ch.sendMessage(chatID, escape(usage))
+		return config.PRTGSensor{}, nil, false
+	}
+	gateway, ok := resolveGateway(fields[0])
+	if !ok {
+		ch.sendMessage(chatID, escape(unknownGatewayMessage(fields[0])))
+		return config.PRTGSensor{}, nil, false
+	}
+	sensor, ok := ch.findPrtgSensor(chatID, gateway, fields[1])
+	if !ok {
+		return config.PRTGSensor{}, nil, false
+	}
+	return sensor, fields[2:], true
+}
+
+// HandlePrtgPause menangani perintah /prtg_pause <gateway> <sensor> <durasi> <pesan>.
+func (ch *CommandHandler) HandlePrtgPause(chatID int64, args, username string) {
+	const usage = "Format: /prtg_pause <gateway> <NIF|IPTX|id> <durasi> <pesan>\nContoh: /prtg_pause jyp IPTX 2h Maintenance link upstream"
+	sensor, rest, ok := ch.parsePrtgTarget(chatID, strings.Fields(args), usage)
+	if !ok {
+		return
+	}
+	if len(rest) < 2 {
+		ch.sendMessage(chatID, escape(usage))
+		return
+	}
+	duration, err := parseRange(rest[0])
+	if err != nil {
+		ch.sendMessage(chatID, escape(err.Error()))
+		return
+	}
+	message := formatPrtgActionMessage(strings.Join(rest[1:], " "), username)
+
+	ctx, cancel := context.WithTimeout(context.Background(), ch.config.PRTGTimeout)
+	defer cancel()
+	if err := ch.repos.PRTG.PauseSensor(ctx, sensor, duration, message); err != nil {
+		slog.Error("Gagal menjeda sensor PRTG", "sensor", sensor.ID, "error", err)
+		ch.sendMessage(chatID, escape(fmt.Sprintf("Gagal menjeda sensor %s: %v", sensor.Name, err)))
+		return
+	}
+	ch.sendMessage(chatID, escape(fmt.Sprintf("⏸️ Sensor %s (%s) dijeda selama %s. Alert Bella untuk sensor ini dibungkam sampai sensor dilanjutkan.", sensor.Name, sensor.ID, formatRange(duration))))
+}
+
+// HandlePrtgResume menangani perintah /prtg_resume <gateway> <sensor>.
+func (ch *CommandHandler) HandlePrtgResume(chatID int64, args string) {
+	const usage = "Format: /prtg_resume <gateway> <NIF|IPTX|id>\nContoh: /prtg_resume jyp IPTX"
+	sensor, _, ok := ch.parsePrtgTarget(chatID, strings.Fields(args), usage)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), ch.config.PRTGTimeout)
+	defer cancel()
+	if err := ch.repos.PRTG.ResumeSensor(ctx, sensor); err != nil {
+		slog.Error("Gagal melanjutkan sensor PRTG", "sensor", sensor.ID, "error", err)
+		ch.sendMessage(chatID, escape(fmt.Sprintf("Gagal melanjutkan sensor %s: %v", sensor.Name, err)))
+		return
+	}
+	ch.sendMessage(chatID, escape(fmt.Sprintf("▶️ Sensor %s (%s) dilanjutkan. Alert Bella aktif kembali.", sensor.Name, sensor.ID)))
+}
+
+// HandlePrtgAck menangani perintah /prtg_ack <gateway> <sensor> <pesan>.
+func (ch *CommandHandler) HandlePrtgAck(chatID int64, args, username string) {
+	const usage = "Format: /prtg_ack <gateway> <NIF|IPTX|id> <pesan>\nContoh: /prtg_ack jyp NIF Sedang dicek tim lapangan"
+	sensor, rest, ok := ch.parsePrtgTarget(chatID, strings.Fields(args), usage)
+	if !ok {
+		return
+	}
+	if len(rest) == 0 {
+		ch.sendMessage(chatID, escape(usage))
+		return
+	}
+	message := formatPrtgActionMessage(strings.Join(rest, " "), username)
+
+	ctx, cancel := context.WithTimeout(context.Background(), ch.config.PRTGTimeout)
+	defer cancel()
+	if err := ch.repos.PRTG.AcknowledgeAlarm(ctx, sensor, message); err != nil {
+		slog.Error("Gagal acknowledge alarm sensor PRTG", "sensor", sensor.ID, "error", err)
+		ch.sendMessage(chatID, escape(fmt.Sprintf("Gagal acknowledge alarm sensor %s: %v", sensor.Name, err)))
+		return
+	}
+	ch.sendMessage(chatID, escape(fmt.Sprintf("✅ Alarm sensor %s (%s) di-acknowledge.", sensor.Name, sensor.ID)))
+}
+
+// formatPrtgActionMessage menambahkan nama pengirim ke pesan pause/acknowledge di PRTG.
+func formatPrtgActionMessage(message, username string) string {
+	if username == "" {
+		return message + " (via Bella)"
+	}
+	return fmt.Sprintf("%s (oleh @%s via Bella)", message, username)
+}
+
+// HandleDevices menangani perintah /devices <gateway> [modulator|demodulator] [filter].
+func (ch *CommandHandler) HandleDevices(chatID int64, args string) {
+	fields := strings.Fields(args)
+	if len(fields) < 1 {
+		ch.sendMessage(chatID, escape("Format: /devices <gateway> [modulator|demodulator] [filter]\nContoh: /devices jyp modulator critical"))
+		return
+	}
+
+	gateway, ok := resolveGateway(fields[0])
+	if !ok {
+		ch.sendMessage(chatID, escape(unknownGatewayMessage(fields[0])))
+		return
+	}
+	repo, ok := ch.repos.ModDemod[gateway]
+	if !ok || repo == nil {
+		ch.sendMessage(chatID, escape(fmt.Sprintf("Database modulator/demodulator untuk gateway %s tidak tersedia.", gateway)))
+		return
+	}
+
+	deviceTypes := []string{"modulator", "demodulator"}
+	rest := fields[1:]
+	if len(rest) > 0 {
+		switch strings.ToLower(rest[0]) {
+		case "modulator", "mod":
+			deviceTypes = []string{"modulator"}
+			rest = rest[1:]
+		case "demodulator", "demod":
+			deviceTypes = []string{"demodulator"}
+			rest = rest[1:]
+		}
+	}
+	filter := strings.ToLower(strings.Join(rest, " "))
+
+	slog.Info("Menangani perintah devices", "gateway", gateway, "types", deviceTypes, "filter", filter)
+
+	inventory := make(map[string][]moddemod.Device)
+	for _, deviceType := range deviceTypes {
+		var devices []moddemod.Device
+		var err error
+		if deviceType == "modulator" {
+			devices, err = repo.ListModulators()
+		} else {
+			devices, err = repo.ListDemodulators()
+		}
+		if err != nil {
+			slog.Error("Gagal mengambil inventaris perangkat", "gateway", gateway, "type", deviceType, "error", err)
+			ch.sendMessage(chatID, escape(fmt.Sprintf("Gagal mengambil data %s dari database.", deviceType)))
+			return
+		}
+		inventory[deviceType] = filterDevices(devices, filter)
+	}
+
+	title := fmt.Sprintf("Inventaris Perangkat %s", gateway)
+	content := FormatDeviceInventory(deviceTypes, inventory)
+	fullMessage := FormatLogMessage(title, content)
+	if len(fullMessage) > telegramMaxMsgLen {
+		slog.Info("Inventaris perangkat terlalu panjang, mengirim sebagai file", "length", len(fullMessage))
+		ch.sendFile(chatID, title, content, fmt.Sprintf("devices_%s.txt", strings.ToLower(gateway)))
+		return
+	}
+	ch.sendMessage(chatID, fullMessage)
+}
+
+// HandleDevice menangani perintah /device <nama>: status terkini perangkat dari
+// database gateway serta riwayat transisi yang dicatat Bella.
+func (ch *CommandHandler) HandleDevice(chatID int64, args string) {
+	name := strings.TrimSpace(args)
+	if name == "" {
+		ch.sendMessage(chatID, escape("Format: /device <nama perangkat>\nContoh: /device JYP-MOD-01"))
+		return
+	}
+
+	slog.Info("Menangani perintah device", "device", name)
+
+	var current *moddemod.Device
+	var gateway, deviceType string
+	for _, botGw := range botGateways {
+		gw := botGw.Gateway()
+		repo, ok := ch.repos.ModDemod[gw]
+		if !ok || repo == nil {
+			continue
+		}
+		device, err := repo.FindModulator(name)
+		if err == nil && device != nil {
+			current, gateway, deviceType = device, gw, "modulator"
+			break
+		}
+		if err != nil {
+			slog.Error("Gagal mencari modulator", "gateway", gw, "device", name, "error", err)
+		}
+		device, err = repo.FindDemodulator(name)
+		if err == nil && device != nil {
+			current, gateway, deviceType = device, gw, "demodulator"
+			break
+		}
+		if err != nil {
+			slog.Error("Gagal mencari demodulator", "gateway", gw, "device", name, "error", err)
+		}
+	}
+
+	var transitions []history.Transition
+	if ch.repos.History != nil {
+		if current != nil {
+			name = current.DeviceName
+			transitions = ch.repos.History.GetTransitions(history.DeviceRef{Gateway: gateway, DeviceType: deviceType, DeviceName: name})
+		} else if refs := ch.repos.History.FindDevices(name); len(refs) > 0 {
+			// Perangkat sudah tidak ada di database; tampilkan riwayat yang terakhir aktif.
+			ref := refs[0]
+			name, gateway, deviceType = ref.DeviceName, ref.Gateway, ref.DeviceType
+			transitions = ch.repos.History.GetTransitions(ref)
+		}
+	}
+
+	if current == nil && len(transitions) == 0 {
+		ch.sendMessage(chatID, escape(fmt.Sprintf("Perangkat '%s' tidak ditemukan.", name)))
+		return
+	}
+
+	ch.sendMessage(chatID, FormatDeviceDetail(name, gateway, deviceType, current, transitions))
+}
+
+// filterDevices menyaring perangkat berdasarkan potongan nama, alarm_state, atau status (up/down).
+func filterDevices(devices []moddemod.Device, filter string) []moddemod.Device {
+	if filter == "" {
+		return devices
+	}
+	var result []moddemod.Device
+	for _, device := range devices {
+		status := "up"
+		if device.IsDown() {
+			status = "down"
+		}
+		if strings.Contains(strings.ToLower(device.DeviceName), filter) ||
+			strings.EqualFold(device.AlarmState, filter) ||
+			status == filter {
+			result = append(result, device)
+		}
+	}
+	return result
+}
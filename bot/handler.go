@@ -3,6 +3,7 @@ package bot
 import (
 	"bella/api"
 	config "bella/config"
+	"bella/internal/state"
 	"fmt"
 	"log/slog"
 	"strconv"
@@ -17,7 +18,7 @@ type BotHandler struct {
 	commandHandler *CommandHandler
 }
 
-func NewBotHandler(config *config.AppConfig, apiClient *api.APIClient) (*BotHandler, error) {
+func NewBotHandler(config *config.AppConfig, apiClients *api.Registry, repos Repositories, stateMgr *state.Manager) (*BotHandler, error) {
 	bot, err := tgbotapi.NewBotAPI(config.TelegramToken)
 	if err != nil {
 		return nil, fmt.Errorf("gagal menginisialisasi bot Telegram: %w", err)
@@ -33,7 +34,7 @@ func NewBotHandler(config *config.AppConfig, apiClient *api.APIClient) (*BotHand
 		}
 	}
 
-	commandHandler := NewCommandHandler(bot, config, apiClient)
+	commandHandler := NewCommandHandler(bot, config, apiClients, repos, stateMgr)
 
 	return &BotHandler{
 		bot:            bot,
@@ -45,12 +46,7 @@ func NewBotHandler(config *config.AppConfig, apiClient *api.APIClient) (*BotHand
 
 // setCommandsForUser mengatur daftar perintah yang terlihat oleh pengguna spesifik.
 func (h *BotHandler) setCommandsForUser(chatID int64, isAuthorized bool) {
-	var commands []tgbotapi.BotCommand
-	if isAuthorized {
-		commands = getAdminCommands()
-	} else {
-		commands = getPublicCommands()
-	}
+	commands := h.commandHandler.commands.BotCommands(isAuthorized)
 
 	// Menggunakan scope untuk menargetkan chat spesifik (pengguna)
 	scope := tgbotapi.NewBotCommandScopeChat(chatID)
@@ -84,67 +80,27 @@ func (h *BotHandler) handleCommand(message *tgbotapi.Message) {
 
 	slog.Info("Menerima perintah", "command", command, "from", message.From.UserName, "user_id", userID)
 
-	// Saat pengguna berinteraksi dengan /start atau /help,
-	// perbarui daftar perintah yang mereka lihat.
-	if command == "start" || command == "help" {
-		h.setCommandsForUser(message.Chat.ID, isAuthorized)
-	}
-
-	// Daftar lengkap perintah admin untuk pengecekan akses
-	adminCommands := map[string]bool{
-		"satria1_gateway_all":   true,
-		"satria1_gateway_jyp":   true,
-		"satria1_gateway_mnk":   true,
-		"satria1_gateway_tmk":   true,
-		"satria1_iptx_jyp":      true,
-		"satria1_iptx_mnk":      true,
-		"satria1_iptx_tmk":      true,
-		"log_error":             true,
-		"log_notif":             true,
-		"log_alerts_active":     true,
-		"log_all":               true,
+	cmd, ok := h.commandHandler.commands.Lookup(command)
+	if !ok {
+		h.commandHandler.sendMessage(message.Chat.ID, escape("Perintah tidak dikenal. Ketik /help untuk melihat daftar perintah."))
+		return
 	}
-
-	// Cek otorisasi HANYA untuk perintah yang terdaftar sebagai admin
-	if adminCommands[command] && !isAuthorized {
+	if !cmd.allowed(isAuthorized) {
 		h.commandHandler.sendMessage(message.Chat.ID, escape("❌ *Akses Ditolak*! Anda tidak memiliki izin untuk menggunakan perintah ini."))
 		return
 	}
 
-	// Routing perintah
-	switch command {
-	case "start", "help":
-		helpMessage := GetHelpMessage(isAuthorized)
-		h.commandHandler.sendMessage(message.Chat.ID, helpMessage)
-	case "myid":
-		h.commandHandler.sendMessage(message.Chat.ID, FormatMyIDMessage(userID))
-
-	// Perintah Gateway (sudah dipastikan terotorisasi)
-	case "satria1_gateway_jyp":
-		go h.commandHandler.HandleGatewaySummary(message.Chat.ID, "Jayapura")
-	case "satria1_gateway_mnk":
-		go h.commandHandler.HandleGatewaySummary(message.Chat.ID, "Manokwari")
-	case "satria1_gateway_tmk":
-		go h.commandHandler.HandleGatewaySummary(message.Chat.ID, "Timika")
-	case "satria1_gateway_all":
-		go h.commandHandler.HandleGatewayAll(message.Chat.ID)
-
-	// Perintah IP Transit (sudah dipastikan terotorisasi)
-	case "satria1_iptx_jyp":
-		go h.commandHandler.HandleIpTransitInfo(message.Chat.ID, "Jayapura")
-	case "satria1_iptx_mnk":
-		go h.commandHandler.HandleIpTransitInfo(message.Chat.ID, "Manokwari")
-	case "satria1_iptx_tmk":
-		go h.commandHandler.HandleIpTransitInfo(message.Chat.ID, "Timika")
-
-	// Perintah Log (sudah dipastikan terotorisasi)
-	case "log_error", "log_notif", "log_alerts_active", "log_all":
-		go h.commandHandler.HandleLogs(message.Chat.ID, command)
-
-	default:
-		// Jangan kirim "perintah tidak dikenal" jika itu adalah perintah admin oleh non-admin
-		if !adminCommands[command] {
-			h.commandHandler.sendMessage(message.Chat.ID, escape("Perintah tidak dikenal. Ketik /help untuk melihat daftar perintah."))
-		}
+	// Perbarui daftar perintah yang dilihat pengguna saat /start atau /help.
+	if cmd.SyncMenu {
+		h.setCommandsForUser(message.Chat.ID, isAuthorized)
 	}
-}
\ No newline at end of file
+
+	cmd.Handler(CommandRequest{
+		ChatID:   message.Chat.ID,
+		UserID:   userID,
+		Username: message.From.UserName,
+		Command:  command,
+		Args:     message.CommandArguments(),
+		IsAdmin:  isAuthorized,
+	})
+}
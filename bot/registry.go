@@ -0,0 +1,241 @@
+package bot
+
+import (
+	"fmt"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Role adalah hak akses minimum untuk menjalankan sebuah perintah.
+type Role int
+
+const (
+	RolePublic Role = iota
+	RoleAdmin
+)
+
+// Kategori bantuan, ditampilkan di /help sesuai urutan helpCategories.
+const (
+	CategoryGateway     = "gateway"
+	CategoryIpTransit   = "iptx"
+	CategoryDetail      = "detail"
+	CategoryPrtgControl = "prtg_control"
+	CategoryLog         = "log"
+	CategoryGeneral     = "general"
+)
+
+type helpCategory struct {
+	Key   string
+	Icon  string
+	Title string
+	// Note adalah catatan MarkdownV2 (sudah di-escape) di bawah daftar perintah.
+	Note string
+}
+
+var helpCategories = []helpCategory{
+	{Key: CategoryGateway, Icon: "🛰️", Title: "Perintah Status Gateway", Note: "_Tambahkan_ `refresh` _untuk melewati cache, mis\\._ `/satria1_gateway_" + botGateways[0].Code + " refresh`"},
+	{Key: CategoryIpTransit, Icon: "📊", Title: "Perintah Info IP Transit", Note: "_Tambahkan_ `refresh` _untuk melewati cache_"},
+	{Key: CategoryDetail, Icon: "🔎", Title: "Perintah Detail"},
+	{Key: CategoryPrtgControl, Icon: "⏸️", Title: "Perintah Kontrol PRTG"},
+	{Key: CategoryLog, Icon: "🛠️", Title: "Perintah Log & Diagnostik"},
+	{Key: CategoryGeneral, Icon: "⚙️", Title: "Perintah Umum"},
+}
+
+// botGateway adalah gateway yang mendapat perintah ringkasan dan IP transit
+// sendiri (/satria1_gateway_<code>, /satria1_iptx_<code>).
+type botGateway struct {
+	Code string
+	Name string
+}
+
+// Gateway mengembalikan nama gateway seperti di config dan database, mis. "JAYAPURA".
+func (g botGateway) Gateway() string {
+	return strings.ToUpper(g.Name)
+}
+
+var botGateways = []botGateway{
+	{Code: "jyp", Name: "Jayapura"},
+	{Code: "mnk", Name: "Manokwari"},
+	{Code: "tmk", Name: "Timika"},
+}
+
+// CommandRequest adalah satu pemanggilan perintah oleh pengguna.
+type CommandRequest struct {
+	ChatID   int64
+	UserID   int64
+	Username string
+	Command  string
+	Args     string
+	IsAdmin  bool
+}
+
+// Command mendeskripsikan satu perintah bot. Routing, otorisasi, menu
+// setMyCommands, dan teks /help semuanya dibangun dari daftar Command.
+type Command struct {
+	Name        string
+	Args        string
+	Description string
+	Role        Role
+	Category    string
+	Handler     func(req CommandRequest)
+	// SyncMenu memperbarui menu perintah pengguna setiap kali perintah dipanggil.
+	SyncMenu bool
+}
+
+// usage mengembalikan perintah beserta argumennya, mis. "/device <nama>".
+func (c Command) usage() string {
+	if c.Args == "" {
+		return "/" + c.Name
+	}
+	return "/" + c.Name + " " + c.Args
+}
+
+// allowed menandakan pengguna dengan status isAdmin boleh menjalankan perintah.
+func (c Command) allowed(isAdmin bool) bool {
+	return c.Role == RolePublic || isAdmin
+}
+
+// CommandRegistry menyimpan perintah sesuai urutan pendaftaran.
+type CommandRegistry struct {
+	commands []Command
+	byName   map[string]Command
+}
+
+func NewCommandRegistry() *CommandRegistry {
+	return &CommandRegistry{byName: make(map[string]Command)}
+}
+
+// Register menambahkan perintah. Nama ganda adalah kesalahan program.
+func (r *CommandRegistry) Register(cmd Command) {
+	if _, exists := r.byName[cmd.Name]; exists {
+		panic(fmt.Sprintf("perintah bot /%s didaftarkan lebih dari sekali", cmd.Name))
+	}
+	r.commands = append(r.commands, cmd)
+	r.byName[cmd.Name] = cmd
+}
+
+func (r *CommandRegistry) Lookup(name string) (Command, bool) {
+	cmd, ok := r.byName[name]
+	return cmd, ok
+}
+
+// Visible mengembalikan perintah yang boleh dijalankan pengguna, sesuai urutan pendaftaran.
+func (r *CommandRegistry) Visible(isAdmin bool) []Command {
+	var visible []Command
+	for _, cmd := range r.commands {
+		if cmd.allowed(isAdmin) {
+			visible = append(visible, cmd)
+		}
+	}
+	return visible
+}
+
+// BotCommands membangun menu setMyCommands untuk pengguna.
+func (r *CommandRegistry) BotCommands(isAdmin bool) []tgbotapi.BotCommand {
+	var commands []tgbotapi.BotCommand
+	for _, cmd := range r.Visible(isAdmin) {
+		description := cmd.Description
+		if cmd.Args != "" {
+			description += ": " + cmd.usage()
+		}
+		commands = append(commands, tgbotapi.BotCommand{Command: cmd.Name, Description: description})
+	}
+	return commands
+}
+
+// registerCommands mendaftarkan semua perintah bot. Perintah atau gateway
+// baru cukup ditambahkan di sini atau di botGateways.
+func registerCommands(r *CommandRegistry, ch *CommandHandler) {
+	r.Register(Command{
+		Name: "satria1_gateway_all", Description: "Ringkasan status semua Gateway",
+		Role: RoleAdmin, Category: CategoryGateway,
+		Handler: func(req CommandRequest) { ch.HandleGatewayAll(req.ChatID, req.Args) },
+	})
+	for _, gw := range botGateways {
+		gwName := gw.Name
+		r.Register(Command{
+			Name: "satria1_gateway_" + gw.Code, Description: "Ringkasan status Gateway " + gw.Name,
+			Role: RoleAdmin, Category: CategoryGateway,
+			Handler: func(req CommandRequest) { ch.HandleGatewaySummary(req.ChatID, gwName, req.Args) },
+		})
+	}
+	for _, gw := range botGateways {
+		gwName := gw.Name
+		r.Register(Command{
+			Name: "satria1_iptx_" + gw.Code, Description: "Info IP Transit Gateway " + gw.Name,
+			Role: RoleAdmin, Category: CategoryIpTransit,
+			Handler: func(req CommandRequest) { ch.HandleIpTransitInfo(req.ChatID, gwName, req.Args) },
+		})
+	}
+
+	r.Register(Command{
+		Name: "satnet", Args: "<gateway> <nama> [range] [chart]", Description: "Detail satnet dan riwayat throughput",
+		Role: RoleAdmin, Category: CategoryDetail,
+		Handler: func(req CommandRequest) { ch.HandleSatnet(req.ChatID, req.Args) },
+	})
+	r.Register(Command{
+		Name: "devices", Args: "<gateway> [tipe] [filter]", Description: "Inventaris modulator/demodulator",
+		Role: RoleAdmin, Category: CategoryDetail,
+		Handler: func(req CommandRequest) { ch.HandleDevices(req.ChatID, req.Args) },
+	})
+	r.Register(Command{
+		Name: "device", Args: "<nama>", Description: "Status dan riwayat alarm perangkat",
+		Role: RoleAdmin, Category: CategoryDetail,
+		Handler: func(req CommandRequest) { ch.HandleDevice(req.ChatID, req.Args) },
+	})
+	r.Register(Command{
+		Name: "prtg", Args: "<gateway> <NIF|IPTX|sensor> [range] [chart]", Description: "Detail dan riwayat sensor PRTG",
+		Role: RoleAdmin, Category: CategoryDetail,
+		Handler: func(req CommandRequest) { ch.HandlePrtg(req.ChatID, req.Args) },
+	})
+
+	r.Register(Command{
+		Name: "prtg_pause", Args: "<gateway> <sensor> <durasi> <pesan>", Description: "Jeda sensor dan bungkam alert",
+		Role: RoleAdmin, Category: CategoryPrtgControl,
+		Handler: func(req CommandRequest) { ch.HandlePrtgPause(req.ChatID, req.Args, req.Username) },
+	})
+	r.Register(Command{
+		Name: "prtg_resume", Args: "<gateway> <sensor>", Description: "Lanjutkan sensor yang dijeda",
+		Role: RoleAdmin, Category: CategoryPrtgControl,
+		Handler: func(req CommandRequest) { ch.HandlePrtgResume(req.ChatID, req.Args) },
+	})
+	r.Register(Command{
+		Name: "prtg_ack", Args: "<gateway> <sensor> <pesan>", Description: "Acknowledge alarm sensor",
+		Role: RoleAdmin, Category: CategoryPrtgControl,
+		Handler: func(req CommandRequest) { ch.HandlePrtgAck(req.ChatID, req.Args, req.Username) },
+	})
+
+	handleLogs := func(req CommandRequest) { ch.HandleLogs(req.ChatID, req.Command) }
+	r.Register(Command{
+		Name: "log_error", Description: fmt.Sprintf("Tampilkan %d log error terakhir", maxFilteredLines),
+		Role: RoleAdmin, Category: CategoryLog, Handler: handleLogs,
+	})
+	r.Register(Command{
+		Name: "log_notif", Description: fmt.Sprintf("Tampilkan %d log notifikasi terakhir", maxFilteredLines),
+		Role: RoleAdmin, Category: CategoryLog, Handler: handleLogs,
+	})
+	r.Register(Command{
+		Name: "log_alerts_active", Description: "Tampilkan semua alert yang aktif",
+		Role: RoleAdmin, Category: CategoryLog, Handler: handleLogs,
+	})
+	r.Register(Command{
+		Name: "log_all", Description: fmt.Sprintf("Tampilkan %d log mentah terakhir", maxLogLines),
+		Role: RoleAdmin, Category: CategoryLog, Handler: handleLogs,
+	})
+
+	sendHelp := func(req CommandRequest) { ch.sendMessage(req.ChatID, GetHelpMessage(r, req.IsAdmin)) }
+	r.Register(Command{
+		Name: "start", Description: "Memulai interaksi dengan bot",
+		Role: RolePublic, Category: CategoryGeneral, Handler: sendHelp, SyncMenu: true,
+	})
+	r.Register(Command{
+		Name: "help", Description: "Menampilkan pesan bantuan ini",
+		Role: RolePublic, Category: CategoryGeneral, Handler: sendHelp, SyncMenu: true,
+	})
+	r.Register(Command{
+		Name: "myid", Description: "Menampilkan ID Telegram Anda",
+		Role: RolePublic, Category: CategoryGeneral,
+		Handler: func(req CommandRequest) { ch.sendMessage(req.ChatID, FormatMyIDMessage(req.UserID)) },
+	})
+}
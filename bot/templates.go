@@ -2,55 +2,52 @@ package bot
 
 import (
 	"bella/api"
+	config "bella/config"
+	"bella/internal/history"
+	"bella/internal/ipcn"
+	"bella/internal/moddemod"
+	"bella/internal/prtgn"
+	"bella/internal/satnet"
+	"bella/internal/state"
+	"bella/internal/types"
 	"fmt"
+	"math"
+	"sort"
 	"strings"
 	"time"
 )
 
-// GetHelpMessage membuat pesan bantuan yang dinamis berdasarkan status otorisasi pengguna.
-func GetHelpMessage(isAdmin bool) string {
+// GetHelpMessage membuat pesan bantuan dari registry perintah, hanya berisi
+// perintah yang boleh dijalankan pengguna.
+func GetHelpMessage(registry *CommandRegistry, isAdmin bool) string {
 	var sb strings.Builder
 
 	sb.WriteString("👋 *Selamat Datang di Bella Bot Monitoring* 👋\n\n")
 	sb.WriteString("Saya adalah asisten virtual untuk memantau kondisi jaringan SATRIA\\-1\\. Berikut adalah daftar perintah yang bisa Anda gunakan:\n\n")
 
-	if isAdmin {
-		// Tampilan untuk Admin
-		sb.WriteString("🛰️ *Perintah Status Gateway*\n")
-		sb.WriteString(escape("───────────────\n"))
-		sb.WriteString("`/satria1_gateway_all` \\- Ringkasan status semua Gateway\n")
-		sb.WriteString("`/satria1_gateway_jyp` \\- Ringkasan status Gateway Jayapura\n")
-		sb.WriteString("`/satria1_gateway_mnk` \\- Ringkasan status Gateway Manokwari\n")
-		sb.WriteString("`/satria1_gateway_tmk` \\- Ringkasan status Gateway Timika\n\n")
-
-		sb.WriteString("📊 *Perintah Info IP Transit*\n")
-		sb.WriteString(escape("───────────────\n"))
-		sb.WriteString("`/satria1_iptx_jyp` \\- Info IP Transit Gateway Jayapura\n")
-		sb.WriteString("`/satria1_iptx_mnk` \\- Info IP Transit Gateway Manokwari\n")
-		sb.WriteString("`/satria1_iptx_tmk` \\- Info IP Transit Gateway Timika\n\n")
-
-		sb.WriteString("🛠️ *Perintah Log & Diagnostik*\n")
-		sb.WriteString(escape("───────────────\n"))
-		sb.WriteString("`/log_error` \\- Tampilkan 15 log error terakhir\n")
-		sb.WriteString("`/log_notif` \\- Tampilkan 15 log notifikasi terakhir\n")
-		sb.WriteString("`/log_alerts_active` \\- Tampilkan semua alert yang aktif\n")
-		sb.WriteString("`/log_all` \\- Tampilkan 20 log mentah terakhir\n\n")
-
-		sb.WriteString("⚙️ *Perintah Umum*\n")
+	byCategory := make(map[string][]Command)
+	for _, cmd := range registry.Visible(isAdmin) {
+		byCategory[cmd.Category] = append(byCategory[cmd.Category], cmd)
+	}
+	for _, category := range helpCategories {
+		commands := byCategory[category.Key]
+		if len(commands) == 0 {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("%s *%s*\n", category.Icon, escape(category.Title)))
 		sb.WriteString(escape("───────────────\n"))
-		sb.WriteString("`/myid` \\- Menampilkan ID Telegram Anda\n")
-		sb.WriteString("`/help` \\- Menampilkan pesan bantuan ini\n\n")
+		for _, cmd := range commands {
+			sb.WriteString(fmt.Sprintf("`%s` \\- %s\n", cmd.usage(), escape(cmd.Description)))
+		}
+		if category.Note != "" {
+			sb.WriteString(category.Note + "\n")
+		}
+		sb.WriteString("\n")
+	}
 
+	if isAdmin {
 		sb.WriteString("💡 *Tips:* Anda memiliki akses penuh sebagai *Admin*\\.\n")
-
 	} else {
-		// Tampilan untuk Pengguna Publik
-		sb.WriteString("🔰 *Perintah*\n")
-		sb.WriteString(escape("───────────────\n"))
-		sb.WriteString("`/start` \\- Memulai interaksi dengan bot\n")
-		sb.WriteString("`/help` \\- Menampilkan pesan bantuan ini\n")
-		sb.WriteString("`/myid` \\- Menampilkan ID Telegram Anda\n\n")
-
 		sb.WriteString("🔒 *Akses Terbatas*\n")
 		sb.WriteString("Anda menggunakan bot sebagai pengguna publik\\. Untuk mendapatkan akses ke fitur admin \\(seperti melihat status gateway dan log\\), silakan berikan ID Telegram Anda kepada administrator sistem\\.\n")
 	}
@@ -148,10 +145,8 @@ func formatIpcnDeviceDetails(data GatewayData, gatewayName string) string {
 	}
 
 	categories := categorizeSensors(data.IpcnSensors, gatewayName)
-	order := []string{"Core Router", "Core Switch", "Management Router", "Management Switch", "Firewall", "VPN Gateway", "CHR Mikrotik", "Sandvine", "Server"}
-
 	hasContent := false
-	for _, name := range order {
+	for _, name := range ipcn.CategoryOrder {
 		category, ok := categories[name]
 		if !ok || category == nil || len(category.Devices) == 0 {
 			continue
@@ -183,10 +178,8 @@ func formatIpcnDeviceSummary(data GatewayData, gatewayName string) string {
 	}
 
 	categories := categorizeSensors(data.IpcnSensors, gatewayName)
-	order := []string{"Core Router", "Core Switch", "Management Router", "Management Switch", "Firewall", "VPN Gateway", "CHR Mikrotik", "Sandvine", "Server"}
-
 	hasContent := false
-	for _, name := range order {
+	for _, name := range ipcn.CategoryOrder {
 		category, ok := categories[name]
 		if !ok || category == nil || len(category.Devices) == 0 {
 			continue
@@ -194,7 +187,7 @@ func formatIpcnDeviceSummary(data GatewayData, gatewayName string) string {
 		hasContent = true
 		up, down := 0, 0
 		for _, device := range category.Devices {
-			if strings.ToLower(device.StatustextPing) == "up" {
+			if ipcn.IsUp(device.StatustextPing) {
 				up++
 			} else {
 				down++
@@ -274,9 +267,31 @@ func formatRtgsAiStatus(data GatewayData) string {
 	return b.String()
 }
 
+// formatDataAge menampilkan umur data jika sebagian data berasal dari cache API.
+func formatDataAge(fetchedAt time.Time) string {
+	if fetchedAt.IsZero() {
+		return ""
+	}
+	age := time.Since(fetchedAt).Truncate(time.Second)
+	if age < time.Second {
+		return ""
+	}
+	return fmt.Sprintf("🕒 _Data cache, diambil %s lalu \\(tambahkan_ `refresh` _untuk data terbaru\\)_\n", escape(age.String()))
+}
+
+// formatUnreachableHubs menampilkan peringatan untuk hub yang tidak dapat dijangkau.
+func formatUnreachableHubs(hubs []string) string {
+	if len(hubs) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("⚠️ *Hub unreachable:* %s\n", escape(strings.Join(hubs, ", ")))
+}
+
 func FormatGatewaySummary(gatewayName string, data GatewayData) string {
 	var b strings.Builder
 	b.WriteString(FormatGatewayHeader(gatewayName))
+	b.WriteString(formatDataAge(data.FetchedAt))
+	b.WriteString(formatUnreachableHubs(data.UnreachableHubs))
 	b.WriteString(formatSystemStatus(data))
 	b.WriteString(formatTrafficInfo(data))
 	b.WriteString(formatIpcnDeviceDetails(data, gatewayName))
@@ -319,3 +334,322 @@ func FormatIpTransitInfo(gatewayName string, status *api.IpcnStatusResponse, tra
 	}
 	return b.String()
 }
+
+// renderSparkline meringkas deret nilai menjadi grafik satu baris dengan lebar tertentu.
+func renderSparkline(values []float64, width int) string {
+	if len(values) == 0 || width <= 0 {
+		return ""
+	}
+	blocks := []rune("▁▂▃▄▅▆▇█")
+
+	if len(values) > width {
+		buckets := make([]float64, width)
+		for i := range buckets {
+			start := i * len(values) / width
+			end := (i + 1) * len(values) / width
+			sum := 0.0
+			for _, v := range values[start:end] {
+				sum += v
+			}
+			buckets[i] = sum / float64(end-start)
+		}
+		values = buckets
+	}
+
+	minVal, maxVal := math.Inf(1), math.Inf(-1)
+	for _, v := range values {
+		minVal = math.Min(minVal, v)
+		maxVal = math.Max(maxVal, v)
+	}
+
+	var b strings.Builder
+	for _, v := range values {
+		idx := 0
+		if maxVal > minVal {
+			idx = int((v - minVal) / (maxVal - minVal) * float64(len(blocks)-1))
+		}
+		b.WriteRune(blocks[idx])
+	}
+	return b.String()
+}
+
+type valueStats struct {
+	Min, Avg, Max float64
+}
+
+func computeStats(values []float64) valueStats {
+	if len(values) == 0 {
+		return valueStats{}
+	}
+	stats := valueStats{Min: math.Inf(1), Max: math.Inf(-1)}
+	sum := 0.0
+	for _, v := range values {
+		stats.Min = math.Min(stats.Min, v)
+		stats.Max = math.Max(stats.Max, v)
+		sum += v
+	}
+	stats.Avg = sum / float64(len(values))
+	return stats
+}
+
+// FormatSatnetDetail memformat balasan perintah /satnet.
+func FormatSatnetDetail(gateway, satnetName string, queryRange time.Duration, history []satnet.Satnet, terminals *satnet.TerminalCount, incident *state.ActiveAlert, withChart bool) string {
+	var b strings.Builder
+	latest := history[len(history)-1]
+
+	b.WriteString(fmt.Sprintf("🛰️ *Satnet %s \\- %s*\n", escape(satnetName), escape(gateway)))
+	b.WriteString(fmt.Sprintf("`      (data %s)`\n", escape(latest.Time.Format("02 Jan 2006 15:04:05"))))
+
+	b.WriteString("\n📊 *Kondisi Terkini*\n")
+	b.WriteString(fmt.Sprintf("`     ┌─ FWD        : %s kbps`\n", escape(fmt.Sprintf("%.2f", latest.FwdThroughput))))
+	b.WriteString(fmt.Sprintf("`     ├─ RTN        : %s kbps`\n", escape(fmt.Sprintf("%.2f", latest.RtnThroughput))))
+	if terminals != nil {
+		b.WriteString(fmt.Sprintf("`     ├─ Online UT  : %d`\n", terminals.Online))
+		b.WriteString(fmt.Sprintf("`     └─ Offline UT : %d`\n", terminals.Offline))
+	} else {
+		b.WriteString("`     └─ UT         : Tidak ada data terminal`\n")
+	}
+
+	fwdValues := make([]float64, len(history))
+	rtnValues := make([]float64, len(history))
+	for i, row := range history {
+		fwdValues[i] = row.FwdThroughput
+		rtnValues[i] = row.RtnThroughput
+	}
+	fwdStats := computeStats(fwdValues)
+	rtnStats := computeStats(rtnValues)
+
+	b.WriteString(fmt.Sprintf("\n📈 *Statistik %s terakhir* \\(%d sampel\\)\n", escape(formatRange(queryRange)), len(history)))
+	b.WriteString(fmt.Sprintf("`     ┌─ FWD min/avg/max : %s kbps`\n", escape(fmt.Sprintf("%.0f / %.0f / %.0f", fwdStats.Min, fwdStats.Avg, fwdStats.Max))))
+	b.WriteString(fmt.Sprintf("`     └─ RTN min/avg/max : %s kbps`\n", escape(fmt.Sprintf("%.0f / %.0f / %.0f", rtnStats.Min, rtnStats.Avg, rtnStats.Max))))
+
+	if withChart {
+		b.WriteString("\n📉 *Grafik FWD*\n")
+		b.WriteString(fmt.Sprintf("`%s`\n", renderSparkline(fwdValues, 30)))
+		b.WriteString("📉 *Grafik RTN*\n")
+		b.WriteString(fmt.Sprintf("`%s`\n", renderSparkline(rtnValues, 30)))
+	}
+
+	b.WriteString("\n🚨 *Insiden Aktif*\n")
+	if incident == nil {
+		b.WriteString(escape("     - Tidak ada insiden aktif.") + "\n")
+	} else {
+		startStr, cause := describeSatnetIncident(*incident)
+		b.WriteString("`     ┌─ Status : DOWN`\n")
+		b.WriteString(fmt.Sprintf("`     ├─ Start  : %s`\n", escape(startStr)))
+		b.WriteString(fmt.Sprintf("`     └─ Cause  : %s`\n", escape(cause)))
+	}
+
+	return b.String()
+}
+
+func describeSatnetIncident(alert state.ActiveAlert) (string, string) {
+	startStr, cause := "N/A", "N/A"
+	switch details := alert.Details.(type) {
+	case types.SatnetDetail:
+		if details.StartIssue != nil {
+			startStr = details.StartIssue.Format("2006/01/02 15:04")
+		}
+		if details.Cause != "" {
+			cause = details.Cause
+		}
+	case map[string]interface{}:
+		if raw, ok := details["start_issue"].(string); ok {
+			if parsed, err := time.Parse(time.RFC3339Nano, raw); err == nil {
+				startStr = parsed.Format("2006/01/02 15:04")
+			}
+		}
+		if raw, ok := details["cause"].(string); ok && raw != "" {
+			cause = raw
+		}
+	}
+	return startStr, cause
+}
+
+// FormatDeviceInventory memformat inventaris perangkat sebagai teks biasa
+// (dipakai di dalam blok kode atau sebagai file).
+func FormatDeviceInventory(deviceTypes []string, inventory map[string][]moddemod.Device) string {
+	var b strings.Builder
+	for i, deviceType := range deviceTypes {
+		devices := inventory[deviceType]
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(fmt.Sprintf("%s (%d)\n", strings.ToUpper(deviceType), len(devices)))
+
+		if len(devices) == 0 {
+			b.WriteString("  Tidak ada perangkat.\n")
+			continue
+		}
+
+		up, down := 0, 0
+		alarmCounts := make(map[string]int)
+		for _, device := range devices {
+			if device.IsDown() {
+				down++
+			} else {
+				up++
+			}
+			alarmState := device.AlarmState
+			if alarmState == "" {
+				alarmState = "none"
+			}
+			alarmCounts[strings.ToLower(alarmState)]++
+		}
+
+		alarmStates := make([]string, 0, len(alarmCounts))
+		for alarmState := range alarmCounts {
+			alarmStates = append(alarmStates, alarmState)
+		}
+		sort.Strings(alarmStates)
+
+		b.WriteString(fmt.Sprintf("  Up: %d | Down: %d\n", up, down))
+		b.WriteString("  Alarm:")
+		for _, alarmState := range alarmStates {
+			b.WriteString(fmt.Sprintf(" %s=%d", alarmState, alarmCounts[alarmState]))
+		}
+		b.WriteString("\n\n")
+
+		for _, device := range devices {
+			status := "UP  "
+			if device.IsDown() {
+				status = "DOWN"
+			}
+			alarmState := device.AlarmState
+			if alarmState == "" {
+				alarmState = "-"
+			}
+			b.WriteString(fmt.Sprintf("  %s %-24s %-9s %s\n", status, device.DeviceName, alarmState, device.UpdatedAt.Format("2006/01/02 15:04")))
+		}
+	}
+	return b.String()
+}
+
+const deviceHistoryLimit = 10
+
+// FormatDeviceDetail memformat balasan perintah /device.
+func FormatDeviceDetail(name, gateway, deviceType string, current *moddemod.Device, transitions []history.Transition) string {
+	var b strings.Builder
+
+	b.WriteString(fmt.Sprintf("📟 *%s \\- %s*\n", escape(name), escape(gateway)))
+	b.WriteString(fmt.Sprintf("`      (%s)`\n", escape(deviceType)))
+
+	b.WriteString("\n📊 *Kondisi Terkini*\n")
+	if current == nil {
+		b.WriteString(escape("     - Perangkat tidak ditemukan di database.") + "\n")
+	} else {
+		status := "UP"
+		if current.IsDown() {
+			status = "DOWN"
+		}
+		alarmState := current.AlarmState
+		if alarmState == "" {
+			alarmState = "-"
+		}
+		b.WriteString(fmt.Sprintf("`     ┌─ Status  : %s`\n", status))
+		b.WriteString(fmt.Sprintf("`     ├─ Alarm   : %s`\n", escape(alarmState)))
+		b.WriteString(fmt.Sprintf("`     └─ Update  : %s`\n", escape(current.UpdatedAt.Format("02 Jan 2006 15:04:05"))))
+	}
+
+	week := history.Summarize(transitions, 7*24*time.Hour)
+	month := history.Summarize(transitions, 30*24*time.Hour)
+	b.WriteString("\n📈 *Ringkasan Gangguan*\n")
+	b.WriteString(fmt.Sprintf("`     ┌─ 7 hari  : down %s, %d kali`\n", escape(formatDowntime(week.Downtime)), week.Flaps))
+	b.WriteString(fmt.Sprintf("`     └─ 30 hari : down %s, %d kali`\n", escape(formatDowntime(month.Downtime)), month.Flaps))
+
+	b.WriteString(fmt.Sprintf("\n🕘 *Riwayat Transisi* \\(%d terakhir\\)\n", deviceHistoryLimit))
+	if len(transitions) == 0 {
+		b.WriteString(escape("     - Belum ada transisi yang tercatat.") + "\n")
+		return b.String()
+	}
+	start := 0
+	if len(transitions) > deviceHistoryLimit {
+		start = len(transitions) - deviceHistoryLimit
+	}
+	for i := len(transitions) - 1; i >= start; i-- {
+		transition := transitions[i]
+		line := fmt.Sprintf("%s %-12s", transition.Time.Format("02/01 15:04"), transition.Event)
+		if transition.AlarmState != "" {
+			line += " " + transition.AlarmState
+		}
+		b.WriteString(fmt.Sprintf("`     %s`\n", escape(line)))
+	}
+	return b.String()
+}
+
+// formatDowntime menampilkan total downtime secara ringkas, mis. "2h 15m".
+func formatDowntime(duration time.Duration) string {
+	if duration <= 0 {
+		return "0m"
+	}
+	duration = duration.Round(time.Minute)
+	days := duration / (24 * time.Hour)
+	hours := (duration % (24 * time.Hour)) / time.Hour
+	minutes := (duration % time.Hour) / time.Minute
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%dd %dh %dm", days, hours, minutes)
+	case hours > 0:
+		return fmt.Sprintf("%dh %dm", hours, minutes)
+	default:
+		return fmt.Sprintf("%dm", minutes)
+	}
+}
+
+// FormatPrtgSensorChoices menampilkan daftar sensor jika pencarian /prtg cocok dengan lebih dari satu sensor.
+func FormatPrtgSensorChoices(gateway, query string, sensors []config.PRTGSensor) string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("🔎 *%d sensor cocok dengan '%s' di %s*\n", len(sensors), escape(query), escape(gateway)))
+	b.WriteString(escape("Gunakan id sensor untuk memilih salah satu:") + "\n\n")
+	for _, sensor := range sensors {
+		b.WriteString(fmt.Sprintf("`%s` %s \\(%s\\)\n", escape(sensor.ID), escape(sensor.Name), escape(sensor.Category)))
+	}
+	return b.String()
+}
+
+// FormatPrtgDetail memformat balasan perintah /prtg.
+func FormatPrtgDetail(status *prtgn.SensorStatus, queryRange time.Duration, points []prtgn.HistoricPoint, withChart bool) string {
+	var b strings.Builder
+
+	b.WriteString(fmt.Sprintf("📡 *PRTG %s \\- %s*\n", escape(status.Sensor.Category), escape(status.Sensor.Gateway)))
+	b.WriteString(fmt.Sprintf("`      %s / %s`\n", escape(status.Device), escape(status.Name)))
+
+	b.WriteString("\n📊 *Kondisi Terkini*\n")
+	b.WriteString(fmt.Sprintf("`     ┌─ Status     : %s`\n", escape(status.Status)))
+	b.WriteString(fmt.Sprintf("`     ├─ Value      : %s`\n", escape(status.LastValue)))
+	b.WriteString(fmt.Sprintf("`     ├─ Last Check : %s`\n", escape(formatPrtgTime(status.LastCheck))))
+	b.WriteString(fmt.Sprintf("`     ├─ Last Up    : %s`\n", escape(formatPrtgTime(status.LastUp))))
+	b.WriteString(fmt.Sprintf("`     └─ Last Down  : %s`\n", escape(formatPrtgTime(status.LastDown))))
+
+	if len(points) == 0 {
+		b.WriteString(fmt.Sprintf("\n📈 *Statistik %s terakhir*\n", escape(formatRange(queryRange))))
+		b.WriteString(escape("     - Data historis tidak tersedia.") + "\n")
+		return b.String()
+	}
+
+	values := make([]float64, len(points))
+	for i, point := range points {
+		values[i] = point.Value
+	}
+	stats := computeStats(values)
+	unit := ""
+	if status.Sensor.Template != config.PRTGTemplateGeneric {
+		unit = " kbps"
+	}
+
+	b.WriteString(fmt.Sprintf("\n📈 *Statistik %s terakhir* \\(%d sampel\\)\n", escape(formatRange(queryRange)), len(points)))
+	b.WriteString(fmt.Sprintf("`     └─ min/avg/max : %s`\n", escape(fmt.Sprintf("%.0f / %.0f / %.0f%s", stats.Min, stats.Avg, stats.Max, unit))))
+
+	if withChart {
+		b.WriteString("\n📉 *Grafik*\n")
+		b.WriteString(fmt.Sprintf("`%s`\n", renderSparkline(values, 30)))
+	}
+	return b.String()
+}
+
+func formatPrtgTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Format("02 Jan 2006 15:04:05")
+}
@@ -0,0 +1,59 @@
+// Command fakehub menjalankan pengganti lokal API G1x dan PRTG untuk
+// menguji perintah bot dan checker tanpa hub produksi.
+//
+//	go run ./cmd/fakehub -addr :8090 -scenario config/fakehub_scenario.example.json
+//
+// Arahkan Bella ke server ini dengan G1G_URL=http://localhost:8090/g1g,
+// G1K_URL=http://localhost:8090/g1k, G1L_URL=http://localhost:8090/g1l, dan
+// PRTG_URL=http://localhost:8090/prtg. Phase dapat diganti manual dengan
+// curl -X POST 'http://localhost:8090/_fake/phase?name=<phase>'.
+package main
+
+import (
+	"bella/internal/fakehub"
+	"context"
+	"flag"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+func main() {
+	addr := flag.String("addr", ":8090", "alamat listen fake hub")
+	scenarioPath := flag.String("scenario", "", "file skenario JSON (kosong = skenario normal bawaan)")
+	flag.Parse()
+
+	scenario := fakehub.DefaultScenario()
+	if *scenarioPath != "" {
+		loaded, err := fakehub.LoadScenario(*scenarioPath)
+		if err != nil {
+			slog.Error("Gagal memuat skenario fake hub", "error", err)
+			os.Exit(1)
+		}
+		scenario = loaded
+	}
+
+	server, err := fakehub.New(scenario)
+	if err != nil {
+		slog.Error("Gagal membuat fake hub", "error", err)
+		os.Exit(1)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	go server.RunScript(ctx)
+
+	httpServer := &http.Server{Addr: *addr, Handler: server}
+	go func() {
+		<-ctx.Done()
+		httpServer.Shutdown(context.Background())
+	}()
+
+	slog.Info("Fake hub berjalan", "addr", *addr, "phases", len(scenario.Phases), "script_steps", len(scenario.Script))
+	if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		slog.Error("Fake hub berhenti dengan error", "error", err)
+		os.Exit(1)
+	}
+}
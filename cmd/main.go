@@ -2,14 +2,15 @@ package main
 
 import (
 	configs "bella/config"
-	"bella/api"
 	"bella/db"
 	"bella/bot"
+	"bella/internal/history"
 	"bella/internal/logger"
 	"bella/internal/notifier"
 	"bella/internal/prtgn"
 	"bella/internal/state"
 	"bella/setup"
+	"context"
 	"log/slog"
 	"os"
 	"os/signal"
@@ -28,20 +29,24 @@ func main() {
 	allConnections := db.InitializeDatabases(config)
 	defer allConnections.CloseAll()
 
-	apiClient := api.NewAPIClient(config.G1kURL, config.APIEmail, config.APIPassword)
-	err := apiClient.Login()
+	apiClients, err := setup.BuildAPIRegistry(config)
 	if err != nil {
-		slog.Error("Koneksi atau login awal ke API gagal.", "error", err.Error())
+		slog.Error("Gagal menyiapkan klien API hub", "error", err)
+		os.Exit(1)
 	}
+	loginCtx, cancelLogin := context.WithTimeout(context.Background(), config.APITimeout)
+	apiClients.LoginAll(loginCtx)
+	cancelLogin()
 
 	stateManager := state.NewManager("logs/active_alerts.json")
+	deviceHistory := history.NewStore("logs/device_history.json")
 	telegramNotifier := notifier.NewTelegramNotifier(config.TelegramToken, config.TelegramChatID)
 
-	satnetServiceMap := setup.RegisterServices(allConnections, telegramNotifier, stateManager)
+	satnetServiceMap := setup.RegisterServices(config, allConnections, apiClients, telegramNotifier, stateManager)
 	prtgAPI := prtgn.NewPRTGAPI(config, telegramNotifier, stateManager)
 
 	scheduler := cron.New()
-	setup.RegisterCronJobs(scheduler, config, satnetServiceMap, prtgAPI, allConnections, telegramNotifier, stateManager)
+	setup.RegisterCronJobs(scheduler, config, satnetServiceMap, prtgAPI, apiClients, allConnections, telegramNotifier, stateManager, deviceHistory)
 	
 	if len(scheduler.Entries()) > 0 {
 		scheduler.Start()
@@ -50,7 +55,8 @@ func main() {
 		slog.Warn("Tidak ada tugas cron yang didaftarkan.")
 	}
 
-	botHandler, err := bot.NewBotHandler(config, apiClient)
+	botRepos := setup.BuildBotRepositories(allConnections, deviceHistory, prtgAPI)
+	botHandler, err := bot.NewBotHandler(config, apiClients, botRepos, stateManager)
 	if err != nil {
 		slog.Error("Gagal membuat bot handler", "error", err)
 		os.Exit(1)
@@ -0,0 +1,67 @@
+package configs
+
+import (
+	"log"
+	"os"
+	"strings"
+)
+
+const (
+	HubG1G = "G1G"
+	HubG1K = "G1K"
+	HubG1L = "G1L"
+
+	// CentralHub melayani data level jaringan untuk semua gateway: CN beacon,
+	// status beam, online UT, modulator/demodulator, dan RTGS AI.
+	CentralHub = HubG1K
+)
+
+// GatewayHubs memetakan gateway ke hub yang melayani data IPCN dan IP transit-nya.
+var GatewayHubs = map[string]string{
+	"JAYAPURA":  HubG1G,
+	"MANOKWARI": HubG1K,
+	"TIMIKA":    HubG1L,
+}
+
+// HubForGateway mengembalikan hub IPCN gateway tanpa membedakan huruf besar/kecil.
+func HubForGateway(gateway string) string {
+	return GatewayHubs[strings.ToUpper(gateway)]
+}
+
+// APIHubConfig adalah koneksi ke API satu hub G1x. Kredensial dibaca dari
+// <HUB>_EMAIL/<HUB>_PASSWORD dengan fallback ke API_EMAIL/API_PASSWORD.
+// TLSInsecure melewati verifikasi sertifikat, CAFile menambahkan CA sendiri.
+type APIHubConfig struct {
+	Name        string
+	URL         string
+	Email       string
+	Password    string
+	TLSInsecure bool
+	CAFile      string
+}
+
+func loadAPIHubs() []APIHubConfig {
+	var hubs []APIHubConfig
+	for _, name := range []string{HubG1G, HubG1K, HubG1L} {
+		hub := APIHubConfig{
+			Name:        name,
+			URL:         getEnv(name + "_URL"),
+			Email:       envWithFallback(name+"_EMAIL", "API_EMAIL"),
+			Password:    envWithFallback(name+"_PASSWORD", "API_PASSWORD"),
+			TLSInsecure: strings.EqualFold(os.Getenv(name+"_TLS_INSECURE"), "true"),
+			CAFile:      os.Getenv(name + "_CA_FILE"),
+		}
+		if hub.Email == "" || hub.Password == "" {
+			log.Fatalf("Error: Kredensial API hub %s kosong. Isi %s_EMAIL/%s_PASSWORD atau API_EMAIL/API_PASSWORD.", name, name, name)
+		}
+		hubs = append(hubs, hub)
+	}
+	return hubs
+}
+
+func envWithFallback(key, fallbackKey string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return os.Getenv(fallbackKey)
+}
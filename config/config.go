@@ -3,7 +3,10 @@ package configs
 import (
 	"log"
 	"os"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -33,12 +36,58 @@ type AppConfig struct {
 	NIF_MNK string
 	NIF_TMK string
 
-	G1gURL string
-	G1kURL string
-	G1lURL string
+	// APIHubs berisi URL, kredensial, dan pengaturan TLS per hub G1x.
+	APIHubs []APIHubConfig
 
-	APIEmail    string
-	APIPassword string
+	APITimeout          time.Duration
+	APIRetryMax         int
+	APIRetryBackoff     time.Duration
+	APIBreakerThreshold int
+	APIBreakerCooldown  time.Duration
+
+	// APICacheTTLs menimpa TTL cache respons API per endpoint (key nama endpoint
+	// huruf kecil, mis. "cn_beacon"). Endpoint yang tidak ada memakai TTL bawaan.
+	APICacheTTLs map[string]time.Duration
+
+	// StaleMaxAges menyimpan umur maksimum data dari STALE_MAX_AGE_<KEY>, dengan
+	// KEY berupa "SUMBER", "SUMBER_GATEWAY", "TABEL", atau "TABEL_GATEWAY".
+	// Lihat StaleMaxAge untuk urutan prioritasnya.
+	StaleMaxAges map[string]time.Duration
+
+	QueryTimeout time.Duration
+
+	// UTWatchlist berisi nama terminal yang dipantau satu per satu.
+	// Kosong berarti pemantauan per-UT nonaktif, "*" di mana pun berarti semua terminal.
+	UTWatchlist     []string
+	UTEsnoThreshold float64
+	UTEsnoWindow    time.Duration
+
+	RainFadeEsnoDropDB float64
+	// CNBeaconMin adalah batas bawah CN beacon bawaan. CNBeaconMins menimpanya
+	// per gateway dari CN_BEACON_MIN_<GATEWAY> untuk klasifikasi rain fade.
+	CNBeaconMin  float64
+	CNBeaconMins map[string]float64
+
+	// KPI gateway dari API G1K. KPIBeamOfflineMax adalah jumlah beam offline
+	// maksimum sebelum alert; penurunan online UT dihitung dari nilai tertinggi
+	// selama KPIUTDropWindow terhadap nilai terakhir.
+	KPIBeamOfflineMax int
+	KPIUTDropPercent  float64
+	KPIUTDropWindow   time.Duration
+
+	RedundancyGroups map[string][]RedundancyGroup
+
+	// PRTGSensors adalah katalog sensor PRTG dari PRTG_SENSORS_FILE, atau
+	// dibangun dari NIF_* dan IPTX_* jika file tidak diset.
+	PRTGSensors []PRTGSensor
+
+	PRTGTimeout     time.Duration
+	PRTGConcurrency int
+
+	// PRTGDiscoveryRules diisi dari PRTG_DISCOVERY_FILE. Sensor hasil discovery
+	// ditambahkan ke PRTGSensors dan diperbarui sesuai PRTGDiscoverySchedule.
+	PRTGDiscoveryRules    []PRTGDiscoveryRule
+	PRTGDiscoverySchedule string
 }
 
 type DatabaseConfig struct {
@@ -55,15 +104,7 @@ func LoadConfig() *AppConfig {
 		log.Println("Peringatan: Tidak dapat menemukan file local.env, akan menggunakan environment variables yang ada.")
 	}
 
-	rawIDs := getEnv("AUTHORIZED_TELEGRAM_IDS")
-	splitIDs := strings.Split(rawIDs, ",")
-	authorizedIDs := make([]string, 0, len(splitIDs))
-	for _, id := range splitIDs {
-		trimmedID := strings.TrimSpace(id)
-		if trimmedID != "" {
-			authorizedIDs = append(authorizedIDs, trimmedID)
-		}
-	}
+	authorizedIDs := splitCommaList(getEnv("AUTHORIZED_TELEGRAM_IDS"))
 
 	cfg := &AppConfig{
 		TelegramToken:         getEnv("TELEGRAM_BELLA_TOKEN"),
@@ -75,20 +116,15 @@ func LoadConfig() *AppConfig {
 		PRTGUrl:      getEnv("PRTG_URL"),
 		PRTGAPITOKEN: getEnv("PRTG_API_TOKEN"),
 
-		IPTX_JYP: getEnv("IPTX_JYP"),
-		IPTX_MNK: getEnv("IPTX_MNK"),
-		IPTX_TMK: getEnv("IPTX_TMK"),
-
-		NIF_JYP: getEnv("NIF_JYP"),
-		NIF_MNK: getEnv("NIF_MNK"),
-		NIF_TMK: getEnv("NIF_TMK"),
+		IPTX_JYP: os.Getenv("IPTX_JYP"),
+		IPTX_MNK: os.Getenv("IPTX_MNK"),
+		IPTX_TMK: os.Getenv("IPTX_TMK"),
 
-		G1gURL: getEnv("G1G_URL"),
-		G1kURL: getEnv("G1K_URL"),
-		G1lURL: getEnv("G1L_URL"),
+		NIF_JYP: os.Getenv("NIF_JYP"),
+		NIF_MNK: os.Getenv("NIF_MNK"),
+		NIF_TMK: os.Getenv("NIF_TMK"),
 
-		APIEmail:    getEnv("API_EMAIL"),
-		APIPassword: getEnv("API_PASSWORD"),
+		APIHubs: loadAPIHubs(),
 	}
 
 	cfg.DBOneJYP = loadDBConfig("DB_ONE_JYP")
@@ -98,9 +134,168 @@ func LoadConfig() *AppConfig {
 	cfg.DBFiveMNK = loadDBConfig("DB_FIVE_MNK")
 	cfg.DBFiveTMK = loadDBConfig("DB_FIVE_TMK")
 
+	cfg.APITimeout = getEnvDuration("API_TIMEOUT", 15*time.Second)
+	cfg.APIRetryMax = getEnvInt("API_RETRY_MAX", 2)
+	cfg.APIRetryBackoff = getEnvDuration("API_RETRY_BACKOFF", 500*time.Millisecond)
+	cfg.APIBreakerThreshold = getEnvInt("API_BREAKER_THRESHOLD", 3)
+	cfg.APIBreakerCooldown = getEnvDuration("API_BREAKER_COOLDOWN", time.Minute)
+	cfg.APICacheTTLs = loadAPICacheTTLs()
+
+	cfg.StaleMaxAges = loadStaleMaxAges()
+	cfg.QueryTimeout = getEnvDuration("QUERY_TIMEOUT", 30*time.Second)
+
+	cfg.UTWatchlist = normalizeWatchlist(splitCommaList(os.Getenv("UT_WATCHLIST")))
+	cfg.UTEsnoThreshold = getEnvFloat("UT_ESNO_THRESHOLD", 3.0)
+	cfg.UTEsnoWindow = getEnvDuration("UT_ESNO_WINDOW", 15*time.Minute)
+
+	cfg.RainFadeEsnoDropDB = getEnvFloat("RAIN_FADE_ESNO_DROP_DB", 3.0)
+	cfg.CNBeaconMin = getEnvFloat("CN_BEACON_MIN", 8.0)
+	cfg.CNBeaconMins = make(map[string]float64)
+	for _, gateway := range gatewayNames() {
+		if os.Getenv("CN_BEACON_MIN_"+gateway) != "" {
+			cfg.CNBeaconMins[gateway] = getEnvFloat("CN_BEACON_MIN_"+gateway, cfg.CNBeaconMin)
+		}
+	}
+	cfg.KPIBeamOfflineMax = getEnvInt("KPI_BEAM_OFFLINE_MAX", 0)
+	cfg.KPIUTDropPercent = getEnvFloat("KPI_UT_DROP_PERCENT", 20)
+	cfg.KPIUTDropWindow = getEnvDuration("KPI_UT_DROP_WINDOW", 15*time.Minute)
+
+	if path := os.Getenv("REDUNDANCY_GROUPS_FILE"); path != "" {
+		groups, err := LoadRedundancyGroups(path)
+		if err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+		cfg.RedundancyGroups = groups
+	}
+
+	if path := os.Getenv("PRTG_SENSORS_FILE"); path != "" {
+		sensors, err := LoadPRTGSensors(path)
+		if err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+		cfg.PRTGSensors = sensors
+	} else {
+		cfg.PRTGSensors = legacyPRTGSensors(cfg)
+	}
+	cfg.PRTGTimeout = getEnvDuration("PRTG_TIMEOUT", 15*time.Second)
+	cfg.PRTGConcurrency = getEnvInt("PRTG_CONCURRENCY", 4)
+
+	if path := os.Getenv("PRTG_DISCOVERY_FILE"); path != "" {
+		rules, err := LoadPRTGDiscoveryRules(path)
+		if err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+		cfg.PRTGDiscoveryRules = rules
+	}
+	cfg.PRTGDiscoverySchedule = os.Getenv("PRTG_DISCOVERY_SCHEDULE")
+	if cfg.PRTGDiscoverySchedule == "" {
+		cfg.PRTGDiscoverySchedule = "@every 1h"
+	}
+
 	return cfg
 }
 
+const (
+	staleMaxAgePrefix  = "STALE_MAX_AGE_"
+	defaultStaleMaxAge = 15 * time.Minute
+)
+
+// loadStaleMaxAges membaca semua variabel STALE_MAX_AGE_<KEY>, sehingga sumber,
+// tabel, dan gateway baru tidak perlu didaftarkan di sini. Nilai "0" atau "off"
+// menonaktifkan pengecekan untuk tabel yang memang jarang berubah.
+func loadStaleMaxAges() map[string]time.Duration {
+	maxAges := make(map[string]time.Duration)
+	for _, env := range os.Environ() {
+		name, value, _ := strings.Cut(env, "=")
+		key, ok := strings.CutPrefix(name, staleMaxAgePrefix)
+		if !ok || key == "" || value == "" {
+			continue
+		}
+		if strings.EqualFold(value, "off") {
+			maxAges[strings.ToUpper(key)] = 0
+			continue
+		}
+		maxAge, err := time.ParseDuration(value)
+		if err != nil {
+			log.Printf("Peringatan: Nilai '%s' untuk '%s' bukan durasi yang valid, diabaikan.", value, name)
+			continue
+		}
+		maxAges[strings.ToUpper(key)] = maxAge
+	}
+	return maxAges
+}
+
+var apiCacheEndpoints = []string{
+	"IPCN_STATUS", "IPTX_TRAFFIC", "ONLINE_UT", "IPCN_SENSOR_STATUS",
+	"DEVICE_PROPERTIES", "CN_BEACON", "BEAM_STATUS", "TERMINAL_STATUS",
+}
+
+// loadAPICacheTTLs membaca API_CACHE_TTL untuk semua endpoint dan
+// API_CACHE_TTL_<ENDPOINT> untuk override per endpoint. 0 menonaktifkan cache.
+func loadAPICacheTTLs() map[string]time.Duration {
+	ttls := make(map[string]time.Duration)
+	for _, endpoint := range apiCacheEndpoints {
+		key := "API_CACHE_TTL_" + endpoint
+		if os.Getenv(key) == "" && os.Getenv("API_CACHE_TTL") == "" {
+			continue
+		}
+		ttls[strings.ToLower(endpoint)] = getEnvDuration(key, getEnvDuration("API_CACHE_TTL", 0))
+	}
+	return ttls
+}
+
+// MonitorAllTerminals menandakan semua terminal di modem_kpi dipantau.
+func (c *AppConfig) MonitorAllTerminals() bool {
+	for _, name := range c.UTWatchlist {
+		if name == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeWatchlist menyederhanakan daftar yang berisi "*" menjadi ["*"],
+// agar wildcard tidak berubah menjadi filter nama jika dicampur nama terminal.
+func normalizeWatchlist(watchlist []string) []string {
+	for _, name := range watchlist {
+		if name == "*" {
+			return []string{"*"}
+		}
+	}
+	return watchlist
+}
+
+// StaleMaxAge mengembalikan umur maksimum data tabel milik sumber di gateway.
+// Override yang paling spesifik menang: TABEL_GATEWAY, TABEL, SUMBER_GATEWAY,
+// lalu SUMBER. Nilai 0 berarti pengecekan kesegaran data dinonaktifkan.
+func (c *AppConfig) StaleMaxAge(source, table, gateway string) time.Duration {
+	source, table, gateway = strings.ToUpper(source), strings.ToUpper(table), strings.ToUpper(gateway)
+	for _, key := range []string{table + "_" + gateway, table, source + "_" + gateway, source} {
+		if maxAge, ok := c.StaleMaxAges[key]; ok {
+			return maxAge
+		}
+	}
+	return defaultStaleMaxAge
+}
+
+// CNBeaconMinFor mengembalikan batas bawah CN beacon untuk gateway.
+func (c *AppConfig) CNBeaconMinFor(gateway string) float64 {
+	if minValue, ok := c.CNBeaconMins[strings.ToUpper(gateway)]; ok {
+		return minValue
+	}
+	return c.CNBeaconMin
+}
+
+// gatewayNames mengembalikan nama gateway yang terdaftar di GatewayHubs, terurut.
+func gatewayNames() []string {
+	names := make([]string, 0, len(GatewayHubs))
+	for gateway := range GatewayHubs {
+		names = append(names, gateway)
+	}
+	sort.Strings(names)
+	return names
+}
+
 func loadDBConfig(prefix string) DatabaseConfig {
 	user := os.Getenv(prefix + "_USERNAME")
 	if user == "" {
@@ -117,6 +312,18 @@ func loadDBConfig(prefix string) DatabaseConfig {
 	}
 }
 
+func splitCommaList(raw string) []string {
+	parts := strings.Split(raw, ",")
+	values := make([]string, 0, len(parts))
+	for _, part := range parts {
+		trimmed := strings.TrimSpace(part)
+		if trimmed != "" {
+			values = append(values, trimmed)
+		}
+	}
+	return values
+}
+
 func getEnv(key string) string {
 	value := os.Getenv(key)
 	if value == "" {
@@ -124,3 +331,42 @@ func getEnv(key string) string {
 	}
 	return value
 }
+
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("Peringatan: Nilai '%s' untuk '%s' bukan durasi yang valid, menggunakan default %s.", value, key, fallback)
+		return fallback
+	}
+	return duration
+}
+
+func getEnvFloat(key string, fallback float64) float64 {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		log.Printf("Peringatan: Nilai '%s' untuk '%s' bukan angka yang valid, menggunakan default %v.", value, key, fallback)
+		return fallback
+	}
+	return parsed
+}
+
+func getEnvInt(key string, fallback int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed < 0 {
+		log.Printf("Peringatan: Nilai '%s' untuk '%s' bukan bilangan bulat non-negatif, menggunakan default %d.", value, key, fallback)
+		return fallback
+	}
+	return parsed
+}
@@ -0,0 +1,32 @@
+package configs
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStaleMaxAge(t *testing.T) {
+	t.Setenv("STALE_MAX_AGE_MODDEMOD", "30m")
+	t.Setenv("STALE_MAX_AGE_MODDEMOD_TIMIKA", "45m")
+	t.Setenv("STALE_MAX_AGE_DEMODULATORS", "2h")
+	t.Setenv("STALE_MAX_AGE_DEMODULATORS_JAYAPURA", "off")
+	t.Setenv("STALE_MAX_AGE_SATNET", "bukan-durasi")
+
+	cfg := &AppConfig{StaleMaxAges: loadStaleMaxAges()}
+	tests := []struct {
+		source, table, gateway string
+		want                   time.Duration
+	}{
+		{"MODDEMOD", "modulators", "MANOKWARI", 30 * time.Minute},
+		{"MODDEMOD", "modulators", "TIMIKA", 45 * time.Minute},
+		{"MODDEMOD", "demodulators", "TIMIKA", 2 * time.Hour},
+		{"MODDEMOD", "demodulators", "Jayapura", 0},
+		{"SATNET", "satnet_kpi", "JAYAPURA", defaultStaleMaxAge},
+		{"TERMINAL", "modem_kpi", "JAYAPURA", defaultStaleMaxAge},
+	}
+	for _, tt := range tests {
+		if got := cfg.StaleMaxAge(tt.source, tt.table, tt.gateway); got != tt.want {
+			t.Errorf("StaleMaxAge(%s, %s, %s) = %s, want %s", tt.source, tt.table, tt.gateway, got, tt.want)
+		}
+	}
+}
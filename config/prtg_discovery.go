@@ -0,0 +1,83 @@
+package configs
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// PRTGDiscoveryRule memetakan sensor PRTG yang ditemukan lewat table.json ke
+// gateway dan kategori. Sensor cocok jika memenuhi semua filter yang diisi
+// (Tag, Group, DevicePattern, SensorPattern). Gateway kosong berarti gateway
+// ditebak dari kode JYP/MNK/TMK di nama device.
+type PRTGDiscoveryRule struct {
+	Tag           string  `json:"tag"`
+	Group         string  `json:"group"`
+	DevicePattern string  `json:"device_pattern"`
+	SensorPattern string  `json:"sensor_pattern"`
+	Gateway       string  `json:"gateway"`
+	Category      string  `json:"category"`
+	Rule          string  `json:"rule"`
+	Threshold     float64 `json:"threshold"`
+	Template      string  `json:"template"`
+
+	Channels []PRTGChannelRule `json:"channels,omitempty"`
+}
+
+// LoadPRTGDiscoveryRules membaca aturan auto-discovery sensor PRTG dari file JSON.
+func LoadPRTGDiscoveryRules(path string) ([]PRTGDiscoveryRule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("gagal membaca file aturan discovery PRTG %s: %w", path, err)
+	}
+
+	var rules []PRTGDiscoveryRule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("gagal parsing file aturan discovery PRTG %s: %w", path, err)
+	}
+
+	for i := range rules {
+		rule := &rules[i]
+		if rule.Tag == "" && rule.Group == "" && rule.DevicePattern == "" {
+			return nil, fmt.Errorf("aturan discovery PRTG #%d: isi minimal salah satu dari tag, group, atau device_pattern", i+1)
+		}
+		for _, pattern := range []string{rule.DevicePattern, rule.SensorPattern} {
+			if pattern == "" {
+				continue
+			}
+			if _, err := regexp.Compile(pattern); err != nil {
+				return nil, fmt.Errorf("aturan discovery PRTG #%d: pattern '%s' tidak valid: %w", i+1, pattern, err)
+			}
+		}
+
+		// Validasi rule/template memakai aturan yang sama dengan katalog statis.
+		probe := rule.Sensor("0", "", "PROBE")
+		if err := normalizePRTGSensor(&probe); err != nil {
+			return nil, fmt.Errorf("aturan discovery PRTG #%d: %w", i+1, err)
+		}
+		rule.Gateway = strings.ToUpper(strings.TrimSpace(rule.Gateway))
+		rule.Category = probe.Category
+		rule.Rule = probe.Rule
+		rule.Template = probe.Template
+	}
+	return rules, nil
+}
+
+// Sensor membuat entri katalog untuk sensor yang cocok dengan aturan ini.
+func (r PRTGDiscoveryRule) Sensor(id, name, gateway string) PRTGSensor {
+	if r.Gateway != "" {
+		gateway = r.Gateway
+	}
+	return PRTGSensor{
+		ID:        id,
+		Name:      name,
+		Gateway:   gateway,
+		Category:  r.Category,
+		Rule:      r.Rule,
+		Threshold: r.Threshold,
+		Template:  r.Template,
+		Channels:  r.Channels,
+	}
+}
@@ -0,0 +1,162 @@
+package configs
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const (
+	PRTGRuleStatus = "status"
+	PRTGRuleMin    = "min"
+	PRTGRuleMax    = "max"
+
+	PRTGTemplateNIF     = "nif"
+	PRTGTemplateTraffic = "traffic"
+	PRTGTemplateGeneric = "generic"
+)
+
+// PRTGSensor adalah satu sensor PRTG yang dipantau Bella.
+// Rule menentukan kapan sensor dianggap bermasalah: "status" hanya memakai
+// status Down dari PRTG, "min"/"max" juga membandingkan lastvalue dengan
+// Threshold. Jika Channels diisi, threshold dievaluasi per channel dan Rule
+// sensor diabaikan. Template memilih format notifikasi.
+//
+// Threshold memakai satuan normal: Kbit/s untuk traffic, byte untuk volume,
+// dan persen untuk nilai persentase.
+type PRTGSensor struct {
+	ID        string            `json:"id"`
+	Name      string            `json:"name"`
+	Gateway   string            `json:"gateway"`
+	Category  string            `json:"category"`
+	Rule      string            `json:"rule"`
+	Threshold float64           `json:"threshold"`
+	Template  string            `json:"template"`
+	Channels  []PRTGChannelRule `json:"channels,omitempty"`
+}
+
+// PRTGChannelRule adalah threshold untuk satu channel sensor, dicocokkan
+// berdasarkan nama channel (mis. "Traffic In (speed)") atau id channel.
+type PRTGChannelRule struct {
+	Channel   string  `json:"channel"`
+	Rule      string  `json:"rule"`
+	Threshold float64 `json:"threshold"`
+}
+
+// LoadPRTGSensors membaca katalog sensor PRTG dari file JSON berisi daftar sensor.
+func LoadPRTGSensors(path string) ([]PRTGSensor, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("gagal membaca file katalog sensor PRTG %s: %w", path, err)
+	}
+
+	var sensors []PRTGSensor
+	if err := json.Unmarshal(data, &sensors); err != nil {
+		return nil, fmt.Errorf("gagal parsing file katalog sensor PRTG %s: %w", path, err)
+	}
+
+	seen := make(map[string]bool, len(sensors))
+	for i := range sensors {
+		if err := normalizePRTGSensor(&sensors[i]); err != nil {
+			return nil, err
+		}
+		if seen[sensors[i].ID] {
+			return nil, fmt.Errorf("sensor PRTG id %s terdaftar lebih dari sekali", sensors[i].ID)
+		}
+		seen[sensors[i].ID] = true
+	}
+	return sensors, nil
+}
+
+func normalizePRTGSensor(sensor *PRTGSensor) error {
+	sensor.ID = strings.TrimSpace(sensor.ID)
+	if sensor.ID == "" {
+		return fmt.Errorf("sensor PRTG '%s' tidak memiliki id", sensor.Name)
+	}
+	sensor.Gateway = strings.ToUpper(strings.TrimSpace(sensor.Gateway))
+	if sensor.Gateway == "" {
+		return fmt.Errorf("sensor PRTG %s tidak memiliki gateway", sensor.ID)
+	}
+	sensor.Category = strings.ToUpper(strings.TrimSpace(sensor.Category))
+	if sensor.Category == "" {
+		return fmt.Errorf("sensor PRTG %s tidak memiliki category", sensor.ID)
+	}
+	if sensor.Name == "" {
+		sensor.Name = fmt.Sprintf("%s %s", sensor.Category, sensor.Gateway)
+	}
+
+	sensor.Rule = strings.ToLower(strings.TrimSpace(sensor.Rule))
+	if sensor.Rule == "" {
+		sensor.Rule = PRTGRuleStatus
+	}
+	if sensor.Rule != PRTGRuleStatus && sensor.Rule != PRTGRuleMin && sensor.Rule != PRTGRuleMax {
+		return fmt.Errorf("sensor PRTG %s: rule harus status, min, atau max", sensor.ID)
+	}
+
+	for i := range sensor.Channels {
+		channel := &sensor.Channels[i]
+		channel.Channel = strings.TrimSpace(channel.Channel)
+		if channel.Channel == "" {
+			return fmt.Errorf("sensor PRTG %s: channel #%d tidak memiliki nama", sensor.ID, i+1)
+		}
+		channel.Rule = strings.ToLower(strings.TrimSpace(channel.Rule))
+		if channel.Rule != PRTGRuleMin && channel.Rule != PRTGRuleMax {
+			return fmt.Errorf("sensor PRTG %s: rule channel '%s' harus min atau max", sensor.ID, channel.Channel)
+		}
+	}
+
+	sensor.Template = strings.ToLower(strings.TrimSpace(sensor.Template))
+	if sensor.Template == "" {
+		sensor.Template = defaultPRTGTemplate(sensor.Category)
+	}
+	if sensor.Template != PRTGTemplateNIF && sensor.Template != PRTGTemplateTraffic && sensor.Template != PRTGTemplateGeneric {
+		return fmt.Errorf("sensor PRTG %s: template harus nif, traffic, atau generic", sensor.ID)
+	}
+	return nil
+}
+
+func defaultPRTGTemplate(category string) string {
+	switch category {
+	case "NIF":
+		return PRTGTemplateNIF
+	case "IPTX":
+		return PRTGTemplateTraffic
+	default:
+		return PRTGTemplateGeneric
+	}
+}
+
+// legacyPRTGSensors membangun katalog dari variabel NIF_* dan IPTX_* untuk
+// deployment yang belum memakai PRTG_SENSORS_FILE.
+func legacyPRTGSensors(cfg *AppConfig) []PRTGSensor {
+	legacy := []struct {
+		category string
+		gateway  string
+		id       string
+	}{
+		{"NIF", "JAYAPURA", cfg.NIF_JYP},
+		{"NIF", "MANOKWARI", cfg.NIF_MNK},
+		{"NIF", "TIMIKA", cfg.NIF_TMK},
+		{"IPTX", "JAYAPURA", cfg.IPTX_JYP},
+		{"IPTX", "MANOKWARI", cfg.IPTX_MNK},
+		{"IPTX", "TIMIKA", cfg.IPTX_TMK},
+	}
+
+	var sensors []PRTGSensor
+	for _, entry := range legacy {
+		if entry.id == "" {
+			continue
+		}
+		sensors = append(sensors, PRTGSensor{
+			ID:        entry.id,
+			Name:      fmt.Sprintf("%s %s", entry.category, entry.gateway),
+			Gateway:   entry.gateway,
+			Category:  entry.category,
+			Rule:      PRTGRuleMin,
+			Threshold: 1000,
+			Template:  defaultPRTGTemplate(entry.category),
+		})
+	}
+	return sensors
+}
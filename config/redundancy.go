@@ -0,0 +1,46 @@
+package configs
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// RedundancyGroup adalah sekumpulan modulator/demodulator yang saling
+// mem-backup, misalnya pasangan main/backup untuk satu nIF.
+type RedundancyGroup struct {
+	Name       string   `json:"name"`
+	DeviceType string   `json:"device_type"`
+	Members    []string `json:"members"`
+}
+
+// LoadRedundancyGroups membaca file JSON berisi grup redundansi per gateway,
+// dengan key nama gateway (JAYAPURA, MANOKWARI, TIMIKA).
+func LoadRedundancyGroups(path string) (map[string][]RedundancyGroup, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("gagal membaca file grup redundansi %s: %w", path, err)
+	}
+
+	var raw map[string][]RedundancyGroup
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("gagal parsing file grup redundansi %s: %w", path, err)
+	}
+
+	groups := make(map[string][]RedundancyGroup, len(raw))
+	for gateway, gatewayGroups := range raw {
+		for i, group := range gatewayGroups {
+			deviceType := strings.ToLower(group.DeviceType)
+			if deviceType != "modulator" && deviceType != "demodulator" {
+				return nil, fmt.Errorf("grup '%s' di gateway %s: device_type harus modulator atau demodulator", group.Name, gateway)
+			}
+			if len(group.Members) == 0 {
+				return nil, fmt.Errorf("grup '%s' di gateway %s tidak memiliki anggota", group.Name, gateway)
+			}
+			gatewayGroups[i].DeviceType = deviceType
+		}
+		groups[strings.ToUpper(gateway)] = gatewayGroups
+	}
+	return groups, nil
+}
@@ -0,0 +1,293 @@
+package fakehub
+
+import (
+	configs "bella/config"
+	"bella/internal/ipcn"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// Duration adalah time.Duration yang dibaca dari string JSON seperti "90s".
+type Duration struct {
+	time.Duration
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var raw string
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("durasi harus berupa string, mis. \"30s\": %w", err)
+	}
+	parsed, err := time.ParseDuration(raw)
+	if err != nil {
+		return err
+	}
+	d.Duration = parsed
+	return nil
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// Scenario adalah skenario yang disajikan fake server. Base adalah kondisi
+// awal; setiap Phase hanya berisi field yang berbeda dari Base dan ditimpakan
+// ke salinan Base saat diaktifkan. Script mengaktifkan phase secara berurutan
+// setelah jeda masing-masing (dipakai di mode standalone).
+type Scenario struct {
+	Email     string   `json:"email"`
+	Password  string   `json:"password"`
+	TokenTTL  Duration `json:"token_ttl"`
+	PRTGToken string   `json:"prtg_token"`
+
+	Base   State                      `json:"base"`
+	Phases map[string]json.RawMessage `json:"phases"`
+	Script []ScriptStep               `json:"script"`
+}
+
+// ScriptStep mengaktifkan Phase setelah After berlalu sejak step sebelumnya.
+type ScriptStep struct {
+	After Duration `json:"after"`
+	Phase string   `json:"phase"`
+}
+
+// State adalah data yang dikembalikan semua endpoint pada satu waktu.
+// Hubs memakai key nama hub huruf kecil ("g1g", "g1k", "g1l").
+type State struct {
+	Hubs map[string]*Hub        `json:"hubs"`
+	PRTG map[string]*PRTGSensor `json:"prtg"`
+}
+
+// Hub adalah data satu hub G1x. Errors memaksa endpoint (nama endpoint
+// api.Endpoint* atau "login") mengembalikan status code tertentu, dan Delay
+// menunda semua respons hub untuk mensimulasikan hub yang lambat.
+type Hub struct {
+	IpTransitMain   string            `json:"ip_transit_main"`
+	IpTransitBackup string            `json:"ip_transit_backup"`
+	NifStatus       string            `json:"nif_status"`
+	NmsStatus       string            `json:"nms_status"`
+	Sensors         map[string]string `json:"sensors"`
+	IptxTraffic     float64           `json:"iptx_traffic"`
+	OnlineUT        []int             `json:"online_ut"`
+	Modulator       DeviceCount       `json:"modulator"`
+	Demodulator     DeviceCount       `json:"demodulator"`
+	CnBeacon        float64           `json:"cn_beacon"`
+	Beam            DeviceCount       `json:"beam"`
+	Terminal        DeviceCount       `json:"terminal"`
+
+	Errors map[string]int `json:"errors"`
+	Delay  Duration       `json:"delay"`
+}
+
+type DeviceCount struct {
+	Online  int `json:"online"`
+	Offline int `json:"offline"`
+}
+
+// PRTGSensor adalah data satu id sensor PRTG. Field pertama membentuk respons
+// getsensordetails.json; LastCheck kosong diisi waktu sekarang dalam format
+// OLE date PRTG. Group dan Tags dipakai filter table.json?content=sensors,
+// Channels menjadi table.json?content=channels (kosong = satu channel "Value"
+// berisi LastValue), dan History adalah nilai tampilan historicdata.json yang
+// disebar rata sampai edate (kosong = LastValue diulang).
+type PRTGSensor struct {
+	Name        string `json:"name"`
+	Device      string `json:"parentdevicename"`
+	LastValue   string `json:"lastvalue"`
+	StatusText  string `json:"statustext"`
+	LastCheck   string `json:"lastcheck"`
+	LastMessage string `json:"lastmessage"`
+	LastUp      string `json:"lastup"`
+	LastDown    string `json:"lastdown"`
+
+	Group    string        `json:"group"`
+	Tags     string        `json:"tags"`
+	Channels []PRTGChannel `json:"channels"`
+	History  []string      `json:"history"`
+}
+
+// PRTGChannel adalah nilai terakhir satu channel sensor.
+type PRTGChannel struct {
+	Name      string `json:"name"`
+	LastValue string `json:"lastvalue"`
+}
+
+// LoadScenario membaca skenario dari file JSON dan memvalidasi phase serta
+// script. File ditimpakan ke DefaultScenario, sehingga "base" cukup berisi
+// bagian yang berbeda dari kondisi normal.
+func LoadScenario(path string) (*Scenario, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("gagal membaca file skenario %s: %w", path, err)
+	}
+
+	scenario := DefaultScenario()
+	file := struct {
+		*Scenario
+		Base json.RawMessage `json:"base"`
+	}{Scenario: scenario}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("gagal parsing file skenario %s: %w", path, err)
+	}
+	if len(file.Base) > 0 {
+		if err := applyOverlay(&scenario.Base, file.Base); err != nil {
+			return nil, fmt.Errorf("base skenario %s tidak valid: %w", path, err)
+		}
+	}
+	if err := scenario.validate(); err != nil {
+		return nil, err
+	}
+	return scenario, nil
+}
+
+func (sc *Scenario) validate() error {
+	for name := range sc.Phases {
+		if _, err := sc.PhaseState(name); err != nil {
+			return err
+		}
+	}
+	for i, step := range sc.Script {
+		if _, ok := sc.Phases[step.Phase]; !ok && step.Phase != "base" {
+			return fmt.Errorf("script step #%d: phase '%s' tidak ditemukan", i+1, step.Phase)
+		}
+	}
+	return nil
+}
+
+// PhaseState mengembalikan Base yang ditimpa phase name. "base" mengembalikan salinan Base.
+func (sc *Scenario) PhaseState(name string) (State, error) {
+	state, err := cloneState(sc.Base)
+	if err != nil {
+		return State{}, err
+	}
+	if name == "base" {
+		return state, nil
+	}
+	raw, ok := sc.Phases[name]
+	if !ok {
+		return State{}, fmt.Errorf("phase '%s' tidak ditemukan", name)
+	}
+	if err := applyOverlay(&state, raw); err != nil {
+		return State{}, fmt.Errorf("phase '%s' tidak valid: %w", name, err)
+	}
+	return state, nil
+}
+
+// applyOverlay menimpakan phase ke state per hub dan per sensor. Unmarshal
+// langsung ke State akan mengganti seluruh isi hub, bukan hanya field yang diisi.
+func applyOverlay(state *State, raw json.RawMessage) error {
+	var overlay struct {
+		Hubs map[string]json.RawMessage `json:"hubs"`
+		PRTG map[string]json.RawMessage `json:"prtg"`
+	}
+	if err := json.Unmarshal(raw, &overlay); err != nil {
+		return err
+	}
+	if state.Hubs == nil {
+		state.Hubs = make(map[string]*Hub)
+	}
+	for name, hubRaw := range overlay.Hubs {
+		hub, ok := state.Hubs[name]
+		if !ok {
+			hub = &Hub{}
+			state.Hubs[name] = hub
+		}
+		if err := json.Unmarshal(hubRaw, hub); err != nil {
+			return fmt.Errorf("hub %s: %w", name, err)
+		}
+	}
+	if state.PRTG == nil {
+		state.PRTG = make(map[string]*PRTGSensor)
+	}
+	for id, sensorRaw := range overlay.PRTG {
+		sensor, ok := state.PRTG[id]
+		if !ok {
+			sensor = &PRTGSensor{}
+			state.PRTG[id] = sensor
+		}
+		if err := json.Unmarshal(sensorRaw, sensor); err != nil {
+			return fmt.Errorf("sensor PRTG %s: %w", id, err)
+		}
+	}
+	return nil
+}
+
+func cloneState(state State) (State, error) {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return State{}, err
+	}
+	var clone State
+	if err := json.Unmarshal(data, &clone); err != nil {
+		return State{}, err
+	}
+	return clone, nil
+}
+
+// DefaultScenario adalah kondisi normal ketiga hub: semua perangkat IPCN up,
+// transit main aktif, dan sensor PRTG sesuai config/prtg_sensors.example.json.
+func DefaultScenario() *Scenario {
+	hubs := make(map[string]*Hub, len(configs.GatewayHubs))
+	for gateway, hub := range configs.GatewayHubs {
+		hubName := strings.ToLower(hub)
+		sensors := make(map[string]string)
+		for _, members := range ipcn.DevicesForGateway(gateway) {
+			for _, member := range members {
+				sensors[member] = "Up"
+			}
+		}
+		hubs[hubName] = &Hub{
+			IpTransitMain:   "Up",
+			IpTransitBackup: "Up",
+			NifStatus:       "Up",
+			NmsStatus:       "Up",
+			Sensors:         sensors,
+			IptxTraffic:     850000,
+			OnlineUT:        []int{1200},
+			Modulator:       DeviceCount{Online: 12},
+			Demodulator:     DeviceCount{Online: 24},
+			CnBeacon:        12.5,
+			Beam:            DeviceCount{Online: 40},
+			Terminal:        DeviceCount{Online: 1180, Offline: 20},
+		}
+	}
+
+	prtg := make(map[string]*PRTGSensor)
+	for id, name := range map[string]string{
+		"2101": "nIF Jayapura", "2102": "nIF Manokwari", "2103": "nIF Timika",
+		"2201": "IP Transit Jayapura", "2202": "IP Transit Manokwari", "2203": "IP Transit Timika",
+	} {
+		prtg[id] = &PRTGSensor{
+			Name:       name,
+			Device:     strings.ToUpper(name),
+			LastValue:  "850,000 kbit/s",
+			StatusText: "Up",
+			Group:      name[strings.LastIndex(name, " ")+1:],
+			Tags:       "bella",
+		}
+	}
+	prtg["2301"] = &PRTGSensor{
+		Name: "Core Switch Jayapura", Device: "CORE SWITCH JAYAPURA", LastValue: "1 msec", StatusText: "Up",
+		Group: "Jayapura", Tags: "bella",
+	}
+	prtg["2401"] = &PRTGSensor{
+		Name: "Uplink Jayapura", Device: "UPLINK JAYAPURA", LastValue: "1,200,000 kbit/s", StatusText: "Up",
+		Group: "Jayapura", Tags: "bella",
+		Channels: []PRTGChannel{
+			{Name: "Traffic In (speed)", LastValue: "1,200,000 kbit/s"},
+			{Name: "Traffic Out (speed)", LastValue: "600,000 kbit/s"},
+			{Name: "Errors In", LastValue: "0 #"},
+			{Name: "Bandwidth Utilization", LastValue: "45 %"},
+		},
+	}
+
+	return &Scenario{
+		Email:     "bella@example.com",
+		Password:  "bella",
+		TokenTTL:  Duration{time.Hour},
+		PRTGToken: "fake-prtg-token",
+		Base:      State{Hubs: hubs, PRTG: prtg},
+	}
+}
@@ -0,0 +1,633 @@
+package fakehub
+
+import (
+	"bella/api"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Server adalah pengganti lokal API G1x dan PRTG. Hub dilayani di bawah
+// prefix nama hub (mis. /g1k/api/v1/ipcn/status) dan PRTG di bawah /prtg,
+// sehingga satu listener cukup untuk G1G_URL, G1K_URL, G1L_URL, dan PRTG_URL.
+type Server struct {
+	scenario *Scenario
+
+	mu     sync.Mutex
+	state  State
+	phase  string
+	tokens map[string]hubToken
+	issued int
+	pauses map[string]prtgPause
+}
+
+// hubToken mengikat token ke hub yang menerbitkannya; token satu hub
+// ditolak oleh hub lain seperti pada G1x sebenarnya.
+type hubToken struct {
+	hub    string
+	expiry time.Time
+}
+
+func New(scenario *Scenario) (*Server, error) {
+	if err := scenario.validate(); err != nil {
+		return nil, err
+	}
+	state, err := scenario.PhaseState("base")
+	if err != nil {
+		return nil, err
+	}
+	return &Server{
+		scenario: scenario,
+		state:    state,
+		phase:    "base",
+		tokens:   make(map[string]hubToken),
+		pauses:   make(map[string]prtgPause),
+	}, nil
+}
+
+// NewHTTPTestServer menjalankan Server pada httptest.Server untuk dipakai dari Go test.
+// Pemanggil wajib memanggil Close pada httptest.Server.
+func NewHTTPTestServer(scenario *Scenario) (*Server, *httptest.Server, error) {
+	srv, err := New(scenario)
+	if err != nil {
+		return nil, nil, err
+	}
+	return srv, httptest.NewServer(srv), nil
+}
+
+// HubURL mengembalikan base URL hub pada server dengan alamat baseURL.
+func HubURL(baseURL, hub string) string {
+	return strings.TrimRight(baseURL, "/") + "/" + strings.ToLower(hub)
+}
+
+// PRTGURL mengembalikan base URL PRTG pada server dengan alamat baseURL.
+func PRTGURL(baseURL string) string {
+	return strings.TrimRight(baseURL, "/") + "/prtg"
+}
+
+// SetPhase mengganti seluruh data dengan phase skenario ("base" untuk kondisi awal).
+func (s *Server) SetPhase(name string) error {
+	state, err := s.scenario.PhaseState(name)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state = state
+	s.phase = name
+	s.pauses = make(map[string]prtgPause)
+	slog.Info("Fake hub berpindah phase", "phase", name)
+	return nil
+}
+
+// Update mengubah data yang sedang disajikan secara langsung.
+func (s *Server) Update(fn func(state *State)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fn(&s.state)
+}
+
+// ExpireTokens membuat semua token yang sudah diterbitkan ditolak dengan 401.
+func (s *Server) ExpireTokens() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokens = make(map[string]hubToken)
+}
+
+// RunScript menjalankan Script skenario sampai selesai atau ctx dibatalkan.
+func (s *Server) RunScript(ctx context.Context) {
+	for _, step := range s.scenario.Script {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(step.After.Duration):
+		}
+		if err := s.SetPhase(step.Phase); err != nil {
+			slog.Error("Gagal menjalankan step script fake hub", "phase", step.Phase, "error", err)
+		}
+	}
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.Trim(r.URL.Path, "/")
+	prefix, rest, _ := strings.Cut(path, "/")
+
+	switch {
+	case prefix == "_fake":
+		s.serveControl(w, r, rest)
+	case prefix == "prtg":
+		s.servePRTG(w, r, "/"+rest)
+	default:
+		s.serveHub(w, r, prefix, "/"+rest)
+	}
+}
+
+// serveControl melayani /_fake/phase?name=... (POST) dan /_fake/state (GET)
+// untuk mengendalikan mode standalone dari curl.
+func (s *Server) serveControl(w http.ResponseWriter, r *http.Request, action string) {
+	switch action {
+	case "phase":
+		if r.Method != http.MethodPost {
+			writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "gunakan POST"})
+			return
+		}
+		if err := s.SetPhase(r.URL.Query().Get("name")); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]string{"phase": r.URL.Query().Get("name")})
+	case "state":
+		s.mu.Lock()
+		body := map[string]interface{}{"phase": s.phase, "state": s.state}
+		data, err := json.Marshal(body)
+		s.mu.Unlock()
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(data)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// hubRoutes memetakan path G1x ke nama endpoint yang dipakai Hub.Errors.
+var hubRoutes = map[string]string{
+	"/api/v1/auth/login":                       "login",
+	"/api/v1/ipcn/status":                      api.EndpointIpcnStatus,
+	"/api/v1/ipcn/sensor-status":               api.EndpointIpcnSensorStatus,
+	"/api/v1/lnm/prtg-data/iptx-traffic":       api.EndpointIptxTraffic,
+	"/api/v1/toa/range-interval":               api.EndpointOnlineUT,
+	"/api/v1/device_properties/status":         api.EndpointDeviceProperties,
+	"/api/v1/lnm/cn_beacon":                    api.EndpointCnBeacon,
+	"/api/v1/terminal/beam-terminal-status":    api.EndpointBeamStatus,
+	"/api/v1/terminal/status/total/integrated": api.EndpointTerminalStatus,
+}
+
+func (s *Server) serveHub(w http.ResponseWriter, r *http.Request, hubName, path string) {
+	endpoint, ok := hubRoutes[path]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	s.mu.Lock()
+	hub, ok := s.state.Hubs[strings.ToLower(hubName)]
+	var delay time.Duration
+	var forcedStatus int
+	if ok {
+		delay = hub.Delay.Duration
+		forcedStatus = hub.Errors[endpoint]
+	}
+	s.mu.Unlock()
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	if delay > 0 {
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+			return
+		}
+	}
+	if forcedStatus != 0 {
+		writeJSON(w, forcedStatus, map[string]interface{}{"status": false, "message": "fake hub: error dipaksa oleh skenario"})
+		return
+	}
+
+	if endpoint == "login" {
+		s.serveLogin(w, r, hubName)
+		return
+	}
+	if !s.authorized(r, hubName) {
+		writeJSON(w, http.StatusUnauthorized, map[string]interface{}{"status": false, "message": "token tidak valid atau kedaluwarsa"})
+		return
+	}
+
+	s.mu.Lock()
+	body := hubResponse(endpoint, hub, r)
+	s.mu.Unlock()
+	writeJSON(w, http.StatusOK, body)
+}
+
+func (s *Server) serveLogin(w http.ResponseWriter, r *http.Request, hubName string) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]interface{}{"status": false, "message": "gunakan POST"})
+		return
+	}
+	var req api.LoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]interface{}{"status": false, "message": "payload login tidak valid"})
+		return
+	}
+	if (s.scenario.Email != "" && req.Email != s.scenario.Email) || (s.scenario.Password != "" && req.Password != s.scenario.Password) {
+		writeJSON(w, http.StatusUnauthorized, map[string]interface{}{"status": false, "message": "email atau password salah"})
+		return
+	}
+
+	ttl := s.scenario.TokenTTL.Duration
+	if ttl <= 0 {
+		ttl = time.Hour
+	}
+	expiry := time.Now().Add(ttl)
+
+	s.mu.Lock()
+	s.issued++
+	token := fakeJWT(s.issued, expiry)
+	s.tokens[token] = hubToken{hub: strings.ToLower(hubName), expiry: expiry}
+	s.mu.Unlock()
+
+	var resp api.LoginResponse
+	resp.Data.Token = token
+	resp.Message = "login berhasil"
+	resp.Status = true
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) authorized(r *http.Request, hubName string) bool {
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	issued, ok := s.tokens[token]
+	return ok && issued.hub == strings.ToLower(hubName) && time.Now().Before(issued.expiry)
+}
+
+// fakeJWT membuat token berformat JWT dengan klaim exp agar APIClient dapat
+// memperbarui token sebelum kedaluwarsa. Signature tidak diverifikasi.
+func fakeJWT(serial int, expiry time.Time) string {
+	encode := base64.RawURLEncoding.EncodeToString
+	header := encode([]byte(`{"alg":"none","typ":"JWT"}`))
+	payload := encode([]byte(fmt.Sprintf(`{"sub":"fakehub-%d","exp":%d}`, serial, expiry.Unix())))
+	return header + "." + payload + ".fakehub"
+}
+
+// hubResponse membangun body respons endpoint dengan bentuk JSON yang sama
+// seperti tipe di package api. Dipanggil dengan s.mu terkunci.
+func hubResponse(endpoint string, hub *Hub, r *http.Request) interface{} {
+	statusText := func(status string) map[string]string {
+		return map[string]string{"statustext": status}
+	}
+
+	switch endpoint {
+	case api.EndpointIpcnStatus:
+		return map[string]interface{}{
+			"ip_transit_main":          statusText(hub.IpTransitMain),
+			"ip_transit_backup_status": statusText(hub.IpTransitBackup),
+			"nif_status":               statusText(hub.NifStatus),
+			"nms_status":               statusText(hub.NmsStatus),
+		}
+	case api.EndpointIpcnSensorStatus:
+		filter := r.URL.Query().Get("device_name")
+		sensors := api.IpcnSensorStatusResponse{}
+		for name, status := range hub.Sensors {
+			if filter != "" && name != filter {
+				continue
+			}
+			sensors = append(sensors, api.IpcnSensorStatus{DeviceName: name, StatustextPing: status})
+		}
+		return sensors
+	case api.EndpointIptxTraffic:
+		return map[string]interface{}{
+			"hisdata": []map[string]float64{{"traffic_total_speed": hub.IptxTraffic}},
+		}
+	case api.EndpointOnlineUT:
+		return map[string]interface{}{"data": onlineUTSeries(hub.OnlineUT, r)}
+	case api.EndpointDeviceProperties:
+		nif := func(count DeviceCount) []map[string]int {
+			return []map[string]int{{"nif_type": 1, "online": count.Online, "offline": count.Offline}}
+		}
+		return map[string]interface{}{
+			"data": []map[string]interface{}{{
+				"modulator":   nif(hub.Modulator),
+				"demodulator": nif(hub.Demodulator),
+			}},
+		}
+	case api.EndpointCnBeacon:
+		return map[string]interface{}{"data": map[string]float64{"value": hub.CnBeacon}}
+	case api.EndpointBeamStatus:
+		return map[string]interface{}{
+			"data": map[string]interface{}{"status_counts": hub.Beam},
+		}
+	case api.EndpointTerminalStatus:
+		return map[string]interface{}{
+			"data": map[string]int{
+				"total":   hub.Terminal.Online + hub.Terminal.Offline,
+				"online":  hub.Terminal.Online,
+				"offline": hub.Terminal.Offline,
+			},
+		}
+	}
+	return map[string]string{}
+}
+
+// onlineUTSeries menyebar nilai OnlineUT sebagai titik berurutan yang berakhir
+// sekarang dengan jarak parameter interval (detik). Nilai terakhir diulang
+// untuk mengisi rentang jika series lebih pendek dari jumlah titik.
+func onlineUTSeries(values []int, r *http.Request) []map[string]interface{} {
+	if len(values) == 0 {
+		return []map[string]interface{}{}
+	}
+	interval := 60 * time.Second
+	if seconds, err := strconv.Atoi(r.URL.Query().Get("interval")); err == nil && seconds > 0 {
+		interval = time.Duration(seconds) * time.Second
+	}
+	points := int(time.Hour / interval)
+	if points < len(values) {
+		points = len(values)
+	}
+
+	now := time.Now().UTC().Truncate(interval)
+	series := make([]map[string]interface{}, 0, points)
+	for i := 0; i < points; i++ {
+		// Nilai diambil dari akhir series sehingga titik terakhir = values[len-1].
+		idx := len(values) - points + i
+		if idx < 0 {
+			idx = 0
+		}
+		series = append(series, map[string]interface{}{
+			"ut_online_toa": values[idx],
+			"created_at":    now.Add(-time.Duration(points-1-i) * interval),
+		})
+	}
+	return series
+}
+
+// prtgRoutes adalah endpoint PRTG yang dipakai prtgn.Client.
+var prtgRoutes = map[string]func(s *Server, w http.ResponseWriter, r *http.Request){
+	"/api/getsensordetails.json": (*Server).servePRTGSensorDetails,
+	"/api/table.json":            (*Server).servePRTGTable,
+	"/api/historicdata.json":     (*Server).servePRTGHistoricData,
+	"/api/pauseobjectfor.htm":    (*Server).servePRTGPause,
+	"/api/pause.htm":             (*Server).servePRTGResume,
+	"/api/acknowledgealarm.htm":  (*Server).servePRTGAcknowledge,
+}
+
+func (s *Server) servePRTG(w http.ResponseWriter, r *http.Request, path string) {
+	if s.scenario.PRTGToken != "" && r.URL.Query().Get("apitoken") != s.scenario.PRTGToken {
+		writePRTGError(w, http.StatusUnauthorized, "apitoken tidak valid")
+		return
+	}
+	handler, ok := prtgRoutes[path]
+	if !ok {
+		writePRTGError(w, http.StatusNotFound, "endpoint tidak didukung fake hub")
+		return
+	}
+	s.resumeExpiredPauses()
+	handler(s, w, r)
+}
+
+// prtgSensor mengembalikan salinan sensor parameter id, atau menulis error
+// PRTG jika sensor tidak ada.
+func (s *Server) prtgSensor(w http.ResponseWriter, r *http.Request) (PRTGSensor, bool) {
+	id := r.URL.Query().Get("id")
+	s.mu.Lock()
+	sensor, ok := s.state.PRTG[id]
+	var copied PRTGSensor
+	if ok {
+		copied = *sensor
+	}
+	s.mu.Unlock()
+	if !ok {
+		writePRTGError(w, http.StatusBadRequest, fmt.Sprintf("sensor id %s tidak ditemukan", id))
+	}
+	return copied, ok
+}
+
+func (s *Server) servePRTGSensorDetails(w http.ResponseWriter, r *http.Request) {
+	details, ok := s.prtgSensor(w, r)
+	if !ok {
+		return
+	}
+	if details.LastCheck == "" {
+		details.LastCheck = fmt.Sprintf("%.6f [0 s ago]", timeToOADate(time.Now()))
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"prtg-version": "fakehub",
+		"sensordata": map[string]string{
+			"name":             details.Name,
+			"parentdevicename": details.Device,
+			"lastvalue":        details.LastValue,
+			"statustext":       details.StatusText,
+			"lastcheck":        details.LastCheck,
+			"lastmessage":      details.LastMessage,
+			"lastup":           details.LastUp,
+			"lastdown":         details.LastDown,
+		},
+	})
+}
+
+// servePRTGTable melayani table.json untuk content=sensors (dengan
+// filter_tags=@tag(x) dan filter_group) dan content=channels.
+func (s *Server) servePRTGTable(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	switch query.Get("content") {
+	case "sensors":
+		tag := strings.TrimSuffix(strings.TrimPrefix(query.Get("filter_tags"), "@tag("), ")")
+		group := query.Get("filter_group")
+
+		s.mu.Lock()
+		rows := []map[string]interface{}{}
+		for id, sensor := range s.state.PRTG {
+			if tag != "" && !slices.Contains(strings.Fields(sensor.Tags), tag) {
+				continue
+			}
+			if group != "" && sensor.Group != group {
+				continue
+			}
+			objID, _ := strconv.Atoi(id)
+			rows = append(rows, map[string]interface{}{
+				"objid": objID, "sensor": sensor.Name, "device": sensor.Device, "group": sensor.Group, "tags": sensor.Tags,
+			})
+		}
+		s.mu.Unlock()
+		sort.Slice(rows, func(i, j int) bool { return rows[i]["objid"].(int) < rows[j]["objid"].(int) })
+		writeJSON(w, http.StatusOK, map[string]interface{}{"prtg-version": "fakehub", "treesize": len(rows), "sensors": rows})
+	case "channels":
+		sensor, ok := s.prtgSensor(w, r)
+		if !ok {
+			return
+		}
+		channels := sensor.Channels
+		if len(channels) == 0 {
+			channels = []PRTGChannel{{Name: "Value", LastValue: sensor.LastValue}}
+		}
+		rows := make([]map[string]interface{}, 0, len(channels))
+		for i, channel := range channels {
+			rows = append(rows, map[string]interface{}{"objid": i, "name": channel.Name, "lastvalue": channel.LastValue})
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"prtg-version": "fakehub", "treesize": len(rows), "channels": rows})
+	default:
+		writePRTGError(w, http.StatusBadRequest, fmt.Sprintf("content '%s' tidak didukung fake hub", query.Get("content")))
+	}
+}
+
+// servePRTGHistoricData menyebar History (atau LastValue) sebagai titik
+// berjarak avg detik yang berakhir sekarang, sepanjang rentang sdate..edate.
+func (s *Server) servePRTGHistoricData(w http.ResponseWriter, r *http.Request) {
+	sensor, ok := s.prtgSensor(w, r)
+	if !ok {
+		return
+	}
+	const layout = "2006-01-02-15-04-05"
+	query := r.URL.Query()
+	start, startErr := time.Parse(layout, query.Get("sdate"))
+	end, endErr := time.Parse(layout, query.Get("edate"))
+	if startErr != nil || endErr != nil || !end.After(start) {
+		writePRTGError(w, http.StatusBadRequest, "sdate/edate tidak valid")
+		return
+	}
+	interval := time.Hour
+	if seconds, err := strconv.Atoi(query.Get("avg")); err == nil && seconds > 0 {
+		interval = time.Duration(seconds) * time.Second
+	}
+
+	values := sensor.History
+	if len(values) == 0 {
+		values = []string{sensor.LastValue}
+	}
+	points := int(end.Sub(start) / interval)
+	if points < 1 {
+		points = 1
+	}
+
+	now := time.Now()
+	rows := make([]map[string]interface{}, 0, points)
+	for i := 0; i < points; i++ {
+		idx := len(values) - points + i
+		if idx < 0 {
+			idx = 0
+		}
+		at := now.Add(-time.Duration(points-1-i) * interval)
+		rows = append(rows, map[string]interface{}{
+			"datetime":     at.Format("2/1/2006 3:04:05 PM"),
+			"datetime_raw": timeToOADate(at),
+			"Value":        values[idx],
+			"coverage":     "100 %",
+		})
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"prtg-version": "fakehub", "treesize": len(rows), "histdata": rows})
+}
+
+// prtgPause menyimpan status sensor sebelum dijeda agar dapat dikembalikan.
+type prtgPause struct {
+	status string
+	until  time.Time
+}
+
+func (s *Server) servePRTGPause(w http.ResponseWriter, r *http.Request) {
+	if _, ok := s.prtgSensor(w, r); !ok {
+		return
+	}
+	minutes, err := strconv.Atoi(r.URL.Query().Get("duration"))
+	if err != nil || minutes < 1 {
+		writePRTGError(w, http.StatusBadRequest, "duration harus berupa menit >= 1")
+		return
+	}
+
+	id := r.URL.Query().Get("id")
+	s.mu.Lock()
+	sensor := s.state.PRTG[id]
+	pause, paused := s.pauses[id]
+	if !paused {
+		pause.status = sensor.StatusText
+	}
+	pause.until = time.Now().Add(time.Duration(minutes) * time.Minute)
+	s.pauses[id] = pause
+	sensor.StatusText = "Paused by User"
+	sensor.LastMessage = r.URL.Query().Get("pausemsg")
+	s.mu.Unlock()
+	writePRTGOK(w)
+}
+
+func (s *Server) servePRTGResume(w http.ResponseWriter, r *http.Request) {
+	if _, ok := s.prtgSensor(w, r); !ok {
+		return
+	}
+	if r.URL.Query().Get("action") != "1" {
+		writePRTGError(w, http.StatusBadRequest, "gunakan pauseobjectfor.htm untuk menjeda sensor")
+		return
+	}
+	s.mu.Lock()
+	s.resumeLocked(r.URL.Query().Get("id"))
+	s.mu.Unlock()
+	writePRTGOK(w)
+}
+
+// servePRTGAcknowledge hanya menerima sensor yang sedang Down, seperti PRTG.
+func (s *Server) servePRTGAcknowledge(w http.ResponseWriter, r *http.Request) {
+	details, ok := s.prtgSensor(w, r)
+	if !ok {
+		return
+	}
+	if !strings.HasPrefix(details.StatusText, "Down") {
+		writePRTGError(w, http.StatusBadRequest, "hanya alarm Down yang dapat di-acknowledge")
+		return
+	}
+	s.mu.Lock()
+	sensor := s.state.PRTG[r.URL.Query().Get("id")]
+	sensor.StatusText = "Down (Acknowledged)"
+	sensor.LastMessage = r.URL.Query().Get("ackmsg")
+	s.mu.Unlock()
+	writePRTGOK(w)
+}
+
+// resumeExpiredPauses melanjutkan sensor yang masa jedanya sudah habis.
+func (s *Server) resumeExpiredPauses() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	for id, pause := range s.pauses {
+		if now.After(pause.until) {
+			s.resumeLocked(id)
+		}
+	}
+}
+
+// resumeLocked mengembalikan status sensor sebelum dijeda. Dipanggil dengan s.mu terkunci.
+func (s *Server) resumeLocked(id string) {
+	pause, ok := s.pauses[id]
+	if !ok {
+		return
+	}
+	delete(s.pauses, id)
+	if sensor, exists := s.state.PRTG[id]; exists {
+		sensor.StatusText = pause.status
+	}
+}
+
+func writePRTGError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"prtg-version": "fakehub", "error": message})
+}
+
+// writePRTGOK menulis respons sukses endpoint .htm, yang pada PRTG berupa HTML kosong.
+func writePRTGOK(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "text/html")
+	w.WriteHeader(http.StatusOK)
+}
+
+// timeToOADate adalah kebalikan prtgn.OADateToTime.
+func timeToOADate(t time.Time) float64 {
+	oleBase := time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+	return float64(t.Sub(oleBase)) / float64(24*time.Hour)
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		slog.Warn("Fake hub gagal menulis respons", "error", err)
+	}
+}
@@ -0,0 +1,314 @@
+package fakehub_test
+
+import (
+	"bella/api"
+	"bella/internal/fakehub"
+	"bella/internal/prtgn"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func startServer(t *testing.T) (*fakehub.Scenario, *fakehub.Server, *httptest.Server) {
+	t.Helper()
+	scenario := fakehub.DefaultScenario()
+	srv, ts, err := fakehub.NewHTTPTestServer(scenario)
+	if err != nil {
+		t.Fatalf("gagal menjalankan fake hub: %v", err)
+	}
+	t.Cleanup(ts.Close)
+	return scenario, srv, ts
+}
+
+func newClient(scenario *fakehub.Scenario, ts *httptest.Server, hub string, opts api.ClientOptions) *api.APIClient {
+	if opts.Timeout == 0 {
+		opts.Timeout = 5 * time.Second
+	}
+	return api.NewAPIClient(fakehub.HubURL(ts.URL, hub), scenario.Email, scenario.Password, opts)
+}
+
+func TestAPIClientLogin(t *testing.T) {
+	scenario, _, ts := startServer(t)
+	ctx := context.Background()
+
+	client := newClient(scenario, ts, "g1k", api.ClientOptions{})
+	if err := client.Login(ctx); err != nil {
+		t.Fatalf("login gagal: %v", err)
+	}
+	status, err := api.GetIpcnStatus(ctx, client)
+	if err != nil {
+		t.Fatalf("GetIpcnStatus gagal: %v", err)
+	}
+	if got := status.IpTransitMain.StatusText; got != "Up" {
+		t.Errorf("ip_transit_main = %q, want Up", got)
+	}
+
+	wrong := api.NewAPIClient(fakehub.HubURL(ts.URL, "g1k"), scenario.Email, "salah", api.ClientOptions{Timeout: 5 * time.Second})
+	if err := wrong.Login(ctx); !api.IsUnauthorized(err) {
+		t.Errorf("login dengan password salah: err = %v, want 401", err)
+	}
+}
+
+func TestAPIClientReloginAfterUnauthorized(t *testing.T) {
+	scenario, srv, ts := startServer(t)
+	ctx := api.WithForceRefresh(context.Background())
+
+	client := newClient(scenario, ts, "g1l", api.ClientOptions{})
+	if _, err := api.GetIpcnStatus(ctx, client); err != nil {
+		t.Fatalf("request pertama gagal: %v", err)
+	}
+	firstToken := client.Token
+
+	srv.ExpireTokens()
+	if _, err := api.GetIpcnStatus(ctx, client); err != nil {
+		t.Fatalf("request setelah token kedaluwarsa gagal: %v", err)
+	}
+	if client.Token == "" || client.Token == firstToken {
+		t.Errorf("token tidak diperbarui setelah 401 (token = %q)", client.Token)
+	}
+}
+
+func TestAPIClientTokenIsBoundToHub(t *testing.T) {
+	scenario, _, ts := startServer(t)
+	ctx := context.Background()
+
+	g1k := newClient(scenario, ts, "g1k", api.ClientOptions{})
+	if err := g1k.Login(ctx); err != nil {
+		t.Fatalf("login gagal: %v", err)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, fakehub.HubURL(ts.URL, "g1g")+"/api/v1/ipcn/status", nil)
+	req.Header.Set("Authorization", "Bearer "+g1k.Token)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("request gagal: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("token G1K di G1G: status = %d, want 401", resp.StatusCode)
+	}
+}
+
+func TestAPIClientBreakerOpensOnScriptedErrors(t *testing.T) {
+	scenario, srv, ts := startServer(t)
+	ctx := api.WithForceRefresh(context.Background())
+
+	srv.Update(func(state *fakehub.State) {
+		state.Hubs["g1k"].Errors = map[string]int{api.EndpointIpcnStatus: http.StatusBadGateway}
+	})
+	opts := api.ClientOptions{BreakerThreshold: 2, BreakerCooldown: time.Minute}
+	client := newClient(scenario, ts, "g1k", opts)
+
+	for i := 0; i < 2; i++ {
+		_, err := api.GetIpcnStatus(ctx, client)
+		var statusErr *api.StatusError
+		if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusBadGateway {
+			t.Fatalf("request #%d: err = %v, want status 502", i+1, err)
+		}
+	}
+	if !client.IsHubUnreachable() {
+		t.Fatal("breaker belum terbuka setelah 2 kegagalan")
+	}
+	if _, err := api.GetIpcnStatus(ctx, client); !errors.Is(err, api.ErrHubUnreachable) {
+		t.Errorf("request saat breaker terbuka: err = %v, want ErrHubUnreachable", err)
+	}
+
+	// Hub lain pada host yang sama tidak ikut ditolak.
+	other := newClient(scenario, ts, "g1l", opts)
+	if _, err := api.GetIpcnStatus(ctx, other); err != nil {
+		t.Errorf("hub G1L ikut gagal: %v", err)
+	}
+}
+
+func TestAPIClientNotFoundDoesNotOpenBreaker(t *testing.T) {
+	scenario, srv, ts := startServer(t)
+	ctx := api.WithForceRefresh(context.Background())
+
+	srv.Update(func(state *fakehub.State) {
+		state.Hubs["g1g"].Errors = map[string]int{api.EndpointCnBeacon: http.StatusNotFound}
+	})
+	client := newClient(scenario, ts, "g1g", api.ClientOptions{BreakerThreshold: 1, BreakerCooldown: time.Minute})
+
+	if _, err := api.GetCnBeacon(ctx, client); err == nil {
+		t.Fatal("GetCnBeacon berhasil, want error 404")
+	}
+	if client.IsHubUnreachable() {
+		t.Error("breaker terbuka karena respons 4xx")
+	}
+}
+
+func TestAPIClientTimeoutOnSlowHub(t *testing.T) {
+	scenario, srv, ts := startServer(t)
+	ctx := api.WithForceRefresh(context.Background())
+
+	client := newClient(scenario, ts, "g1g", api.ClientOptions{Timeout: 100 * time.Millisecond})
+	if err := client.Login(ctx); err != nil {
+		t.Fatalf("login gagal: %v", err)
+	}
+	srv.Update(func(state *fakehub.State) {
+		state.Hubs["g1g"].Delay = fakehub.Duration{Duration: time.Second}
+	})
+
+	start := time.Now()
+	_, err := api.GetIpcnStatus(ctx, client)
+	if err == nil {
+		t.Fatal("request ke hub lambat berhasil, want timeout")
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("request baru gagal setelah %s, want sesuai timeout klien", elapsed)
+	}
+}
+
+func TestAPIClientCallerDeadlineOnSlowHub(t *testing.T) {
+	scenario, srv, ts := startServer(t)
+
+	client := newClient(scenario, ts, "g1l", api.ClientOptions{})
+	if err := client.Login(context.Background()); err != nil {
+		t.Fatalf("login gagal: %v", err)
+	}
+	srv.Update(func(state *fakehub.State) {
+		state.Hubs["g1l"].Delay = fakehub.Duration{Duration: time.Second}
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err := api.GetIpcnStatus(ctx, client)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func newPRTGClient(scenario *fakehub.Scenario, ts *httptest.Server) *prtgn.Client {
+	return prtgn.NewClient(fakehub.PRTGURL(ts.URL), scenario.PRTGToken, 5*time.Second)
+}
+
+func TestPRTGSensorDetailsAndToken(t *testing.T) {
+	scenario, _, ts := startServer(t)
+	ctx := context.Background()
+
+	details, err := newPRTGClient(scenario, ts).GetSensorDetails(ctx, "2101")
+	if err != nil {
+		t.Fatalf("GetSensorDetails gagal: %v", err)
+	}
+	if details.Name != "nIF Jayapura" || details.StatusText != "Up" || details.LastCheck == "" {
+		t.Errorf("detail sensor tidak sesuai: %+v", details)
+	}
+
+	bad := prtgn.NewClient(fakehub.PRTGURL(ts.URL), "token-rahasia", 5*time.Second)
+	_, err = bad.GetSensorDetails(ctx, "2101")
+	var apiErr *prtgn.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("token salah: err = %v, want APIError 401", err)
+	}
+	if strings.Contains(err.Error(), "token-rahasia") {
+		t.Errorf("error memuat apitoken: %v", err)
+	}
+}
+
+func TestPRTGTableSensorsAndChannels(t *testing.T) {
+	scenario, _, ts := startServer(t)
+	ctx := context.Background()
+	client := newPRTGClient(scenario, ts)
+
+	sensors, err := client.ListSensors(ctx, "bella", "Jayapura")
+	if err != nil {
+		t.Fatalf("ListSensors gagal: %v", err)
+	}
+	var ids []int
+	for _, sensor := range sensors {
+		ids = append(ids, sensor.ObjID)
+	}
+	want := []int{2101, 2201, 2301, 2401}
+	if len(ids) != len(want) {
+		t.Fatalf("sensor Jayapura = %v, want %v", ids, want)
+	}
+	for i := range want {
+		if ids[i] != want[i] {
+			t.Fatalf("sensor Jayapura = %v, want %v", ids, want)
+		}
+	}
+
+	channels, err := client.GetChannels(ctx, "2401")
+	if err != nil {
+		t.Fatalf("GetChannels gagal: %v", err)
+	}
+	if len(channels) != 4 || channels[0].Name != "Traffic In (speed)" {
+		t.Errorf("channel sensor 2401 tidak sesuai: %+v", channels)
+	}
+
+	channels, err = client.GetChannels(ctx, "2101")
+	if err != nil {
+		t.Fatalf("GetChannels tanpa channel skenario gagal: %v", err)
+	}
+	if len(channels) != 1 || channels[0].LastValue != "850,000 kbit/s" {
+		t.Errorf("channel bawaan sensor 2101 tidak sesuai: %+v", channels)
+	}
+}
+
+func TestPRTGHistoricData(t *testing.T) {
+	scenario, srv, ts := startServer(t)
+	srv.Update(func(state *fakehub.State) {
+		state.PRTG["2201"].History = []string{"100 kbit/s", "200 kbit/s", "300 kbit/s"}
+	})
+
+	end := time.Now()
+	rows, err := newPRTGClient(scenario, ts).GetHistoricData(context.Background(), "2201", end.Add(-time.Hour), end, 600)
+	if err != nil {
+		t.Fatalf("GetHistoricData gagal: %v", err)
+	}
+	if len(rows) != 6 {
+		t.Fatalf("jumlah titik = %d, want 6", len(rows))
+	}
+	if got := rows[len(rows)-1]["Value"]; got != "300 kbit/s" {
+		t.Errorf("titik terakhir = %v, want 300 kbit/s", got)
+	}
+	if _, ok := rows[0]["datetime_raw"].(float64); !ok {
+		t.Errorf("datetime_raw tidak berupa angka: %v", rows[0]["datetime_raw"])
+	}
+}
+
+func TestPRTGPauseResumeAndAcknowledge(t *testing.T) {
+	scenario, srv, ts := startServer(t)
+	ctx := context.Background()
+	client := newPRTGClient(scenario, ts)
+
+	status := func(id string) string {
+		t.Helper()
+		details, err := client.GetSensorDetails(ctx, id)
+		if err != nil {
+			t.Fatalf("GetSensorDetails gagal: %v", err)
+		}
+		return details.StatusText
+	}
+
+	if err := client.PauseSensor(ctx, "2102", 30*time.Minute, "maintenance"); err != nil {
+		t.Fatalf("PauseSensor gagal: %v", err)
+	}
+	if got := status("2102"); !strings.HasPrefix(got, "Paused") {
+		t.Errorf("status setelah pause = %q, want Paused", got)
+	}
+	if err := client.ResumeSensor(ctx, "2102"); err != nil {
+		t.Fatalf("ResumeSensor gagal: %v", err)
+	}
+	if got := status("2102"); got != "Up" {
+		t.Errorf("status setelah resume = %q, want Up", got)
+	}
+
+	var apiErr *prtgn.APIError
+	if err := client.AcknowledgeAlarm(ctx, "2103", "dicek"); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest {
+		t.Errorf("acknowledge sensor Up: err = %v, want APIError 400", err)
+	}
+	srv.Update(func(state *fakehub.State) {
+		state.PRTG["2103"].StatusText = "Down"
+	})
+	if err := client.AcknowledgeAlarm(ctx, "2103", "dicek"); err != nil {
+		t.Fatalf("AcknowledgeAlarm gagal: %v", err)
+	}
+	if got := status("2103"); got != "Down (Acknowledged)" {
+		t.Errorf("status setelah acknowledge = %q, want Down (Acknowledged)", got)
+	}
+}
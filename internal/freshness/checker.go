@@ -0,0 +1,114 @@
+package freshness
+
+import (
+	"bella/internal/notifier"
+	"bella/internal/state"
+	"bella/internal/types"
+	"bella/internal/wib"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// Checker memantau umur data terakhir dari setiap sumber data dan mengelola
+// siklus hidup alert "DATA STALE" secara terpisah dari alert perangkat.
+type Checker struct {
+	notifier notifier.Notifier
+	state    *state.Manager
+}
+
+func NewChecker(notifier notifier.Notifier, stateMgr *state.Manager) *Checker {
+	return &Checker{
+		notifier: notifier,
+		state:    stateMgr,
+	}
+}
+
+// Age menghitung umur data dengan menganggap jam dinding timestamp sebagai WIB,
+// sama seperti perhitungan durasi pada notifier.
+func Age(lastData time.Time) time.Duration {
+	wallClock := time.Date(lastData.Year(), lastData.Month(), lastData.Day(),
+		lastData.Hour(), lastData.Minute(), lastData.Second(), lastData.Nanosecond(), wib.Location())
+	return time.Since(wallClock)
+}
+
+// Evaluate mengembalikan true jika data dari sumber masih segar. Jika data
+// melebihi maxAge, alert stale dibuka sekali dan pemanggil sebaiknya
+// melewati evaluasi alert lain agar tidak melaporkan data lama sebagai kondisi terkini.
+func (c *Checker) Evaluate(source, gateway string, lastData time.Time, maxAge time.Duration) bool {
+	if maxAge <= 0 {
+		return true
+	}
+
+	alertKey := StaleKey(source, gateway)
+	previousAlert, wasStale := c.state.GetAlertByKey(alertKey)
+
+	isStale := lastData.IsZero() || Age(lastData) > maxAge
+	if isStale {
+		if !wasStale {
+			slog.Warn("Data sumber terdeteksi STALE, mengirim notifikasi...", "source", source, "gateway", gateway, "last_data", lastData, "max_age", maxAge)
+			alert := types.StaleDataAlert{
+				Source:      source,
+				GatewayName: gateway,
+				LastData:    lastData,
+				MaxAge:      maxAge,
+				DetectedAt:  time.Now(),
+			}
+			if err := c.notifier.SendStaleDataAlert(alert); err != nil {
+				slog.Error("Gagal mengirim notifikasi data stale", "source", source, "gateway", gateway, "error", err)
+			}
+			c.state.AddAlert(alertKey, state.ActiveAlert{
+				Type:    "stale",
+				Gateway: gateway,
+				Details: alert,
+			})
+		} else {
+			slog.Warn("Data sumber masih STALE, evaluasi alert dilewati", "source", source, "gateway", gateway, "last_data", lastData)
+		}
+		return false
+	}
+
+	if wasStale {
+		slog.Info("Data sumber kembali SEGAR", "source", source, "gateway", gateway, "last_data", lastData)
+		var staleSince time.Time
+		switch details := previousAlert.Details.(type) {
+		case types.StaleDataAlert:
+			staleSince = details.LastData
+		case map[string]interface{}:
+			if lastDataStr, ok := details["last_data"].(string); ok {
+				if parsed, err := time.Parse(time.RFC3339Nano, lastDataStr); err == nil {
+					staleSince = parsed
+				}
+			}
+		}
+		upAlert := types.StaleDataUpAlert{
+			Source:       source,
+			GatewayName:  gateway,
+			StaleSince:   staleSince,
+			RecoveryTime: lastData,
+		}
+		if err := c.notifier.SendStaleDataUpAlert(upAlert); err != nil {
+			slog.Error("Gagal mengirim notifikasi data kembali segar", "source", source, "gateway", gateway, "error", err)
+		}
+		c.state.RemoveAlertByKey(alertKey)
+	}
+	return true
+}
+
+// Prune menghapus alert stale milik sumber berawalan prefix yang tidak lagi
+// dipantau. keep berisi key StaleKey dari sumber yang masih aktif.
+func (c *Checker) Prune(prefix string, keep map[string]bool) {
+	keyPrefix := "stale_" + strings.ToLower(prefix)
+	for key := range c.state.GetActiveAlerts() {
+		if strings.HasPrefix(key, keyPrefix) && !keep[key] {
+			slog.Info("Sumber data tidak lagi dipantau, alert stale dihapus", "key", key)
+			c.state.RemoveAlertByKey(key)
+		}
+	}
+}
+
+// StaleKey adalah key state alert stale untuk sumber di gateway.
+func StaleKey(source, gateway string) string {
+	return fmt.Sprintf("stale_%s_%s", strings.ToLower(source), gateway)
+}
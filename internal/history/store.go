@@ -0,0 +1,224 @@
+package history
+
+import (
+	"bella/internal/wib"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	EventDown        = "DOWN"
+	EventUp          = "UP"
+	EventAlarmChange = "ALARM_CHANGE"
+
+	retention    = 31 * 24 * time.Hour
+	maxPerDevice = 500
+)
+
+// Transition adalah satu perubahan status perangkat. Time mengikuti timestamp
+// sumber data (jam dinding WIB), RecordedAt adalah waktu Bella mencatatnya.
+type Transition struct {
+	Gateway    string    `json:"gateway"`
+	DeviceType string    `json:"device_type"`
+	DeviceName string    `json:"device_name"`
+	Event      string    `json:"event"`
+	Status     int       `json:"status"`
+	AlarmState string    `json:"alarm_state"`
+	Time       time.Time `json:"time"`
+	RecordedAt time.Time `json:"recorded_at"`
+}
+
+// Summary merangkum riwayat perangkat dalam satu window waktu.
+type Summary struct {
+	Downtime time.Duration
+	Flaps    int
+}
+
+type Store struct {
+	filePath    string
+	mu          sync.Mutex
+	transitions map[string][]Transition
+}
+
+func NewStore(filePath string) *Store {
+	s := &Store{
+		filePath:    filePath,
+		transitions: make(map[string][]Transition),
+	}
+	if err := s.load(); err != nil {
+		slog.Warn("Tidak dapat memuat file riwayat perangkat, memulai dengan riwayat kosong.", "file", filePath, "error", err)
+	}
+	return s
+}
+
+func (s *Store) load() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := os.ReadFile(s.filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	if len(data) == 0 {
+		return nil
+	}
+	var loaded map[string][]Transition
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		return err
+	}
+	// File lama memakai nama perangkat saja sebagai key; semua riwayat
+	// dikelompokkan ulang dengan deviceKey.
+	for _, transitions := range loaded {
+		for _, transition := range transitions {
+			key := deviceKey(transition.Gateway, transition.DeviceType, transition.DeviceName)
+			s.transitions[key] = append(s.transitions[key], transition)
+		}
+	}
+	for _, transitions := range s.transitions {
+		sort.SliceStable(transitions, func(i, j int) bool { return transitions[i].Time.Before(transitions[j].Time) })
+	}
+	return nil
+}
+
+// deviceKey membedakan perangkat dengan nama sama di gateway atau tipe berbeda.
+func deviceKey(gateway, deviceType, deviceName string) string {
+	return strings.ToUpper(gateway) + "/" + strings.ToLower(deviceType) + "/" + deviceName
+}
+
+// DeviceRef mengidentifikasi satu perangkat di riwayat.
+type DeviceRef struct {
+	Gateway    string
+	DeviceType string
+	DeviceName string
+}
+
+func (s *Store) save() error {
+	data, err := json.Marshal(s.transitions)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.filePath, data, 0644)
+}
+
+// Record menyimpan transisi baru dan membuang riwayat yang lebih tua dari masa retensi.
+func (s *Store) Record(transition Transition) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if transition.RecordedAt.IsZero() {
+		transition.RecordedAt = time.Now()
+	}
+
+	key := deviceKey(transition.Gateway, transition.DeviceType, transition.DeviceName)
+	cutoff := wallClockNow().Add(-retention)
+	kept := make([]Transition, 0, len(s.transitions[key])+1)
+	for _, existing := range s.transitions[key] {
+		if existing.Time.After(cutoff) {
+			kept = append(kept, existing)
+		}
+	}
+	kept = append(kept, transition)
+	sort.SliceStable(kept, func(i, j int) bool { return kept[i].Time.Before(kept[j].Time) })
+	if len(kept) > maxPerDevice {
+		kept = kept[len(kept)-maxPerDevice:]
+	}
+	s.transitions[key] = kept
+
+	if err := s.save(); err != nil {
+		slog.Error("Gagal menyimpan file riwayat perangkat", "file", s.filePath, "device", transition.DeviceName, "error", err)
+	}
+}
+
+// GetTransitions mengembalikan salinan riwayat satu perangkat, urut dari yang terlama.
+func (s *Store) GetTransitions(ref DeviceRef) []Transition {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	transitions := s.transitions[deviceKey(ref.Gateway, ref.DeviceType, ref.DeviceName)]
+	clone := make([]Transition, len(transitions))
+	copy(clone, transitions)
+	return clone
+}
+
+// FindDevices mencari perangkat di riwayat berdasarkan nama tanpa membedakan
+// huruf besar/kecil. Hasil diurutkan dari transisi terakhir yang paling baru.
+func (s *Store) FindDevices(name string) []DeviceRef {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var refs []DeviceRef
+	latest := make(map[DeviceRef]time.Time)
+	for _, transitions := range s.transitions {
+		if len(transitions) == 0 {
+			continue
+		}
+		last := transitions[len(transitions)-1]
+		if !strings.EqualFold(last.DeviceName, name) {
+			continue
+		}
+		ref := DeviceRef{Gateway: last.Gateway, DeviceType: last.DeviceType, DeviceName: last.DeviceName}
+		refs = append(refs, ref)
+		latest[ref] = last.Time
+	}
+	sort.Slice(refs, func(i, j int) bool { return latest[refs[i]].After(latest[refs[j]]) })
+	return refs
+}
+
+// Summarize menghitung total downtime dan jumlah kejadian down dalam window.
+// Gangguan yang masih berlangsung dihitung sampai sekarang.
+func Summarize(transitions []Transition, window time.Duration) Summary {
+	now := wallClockNow()
+	windowStart := now.Add(-window)
+
+	var summary Summary
+	var downSince *time.Time
+	for _, transition := range transitions {
+		switch transition.Event {
+		case EventDown:
+			if downSince == nil {
+				start := transition.Time
+				downSince = &start
+				if transition.Time.After(windowStart) {
+					summary.Flaps++
+				}
+			}
+		case EventUp:
+			if downSince != nil {
+				summary.Downtime += overlap(*downSince, transition.Time, windowStart, now)
+				downSince = nil
+			}
+		}
+	}
+	if downSince != nil {
+		summary.Downtime += overlap(*downSince, now, windowStart, now)
+	}
+	return summary
+}
+
+func overlap(start, end, windowStart, windowEnd time.Time) time.Duration {
+	if start.Before(windowStart) {
+		start = windowStart
+	}
+	if end.After(windowEnd) {
+		end = windowEnd
+	}
+	if end.Before(start) {
+		return 0
+	}
+	return end.Sub(start)
+}
+
+// wallClockNow mengembalikan waktu sekarang dalam konvensi timestamp database,
+// yaitu jam dinding WIB yang dibaca sebagai UTC.
+func wallClockNow() time.Time {
+	now := wib.Now()
+	return time.Date(now.Year(), now.Month(), now.Day(), now.Hour(), now.Minute(), now.Second(), now.Nanosecond(), time.UTC)
+}
@@ -0,0 +1,60 @@
+package ipcn
+
+import "strings"
+
+// CategoryOrder adalah urutan tampilan kategori perangkat IPCN.
+var CategoryOrder = []string{"Core Router", "Core Switch", "Management Router", "Management Switch", "Firewall", "VPN Gateway", "CHR Mikrotik", "Sandvine", "Server"}
+
+// DeviceMapping memetakan nama perangkat IPCN (device_name pada sensor-status)
+// ke kategorinya per gateway. Kategori dengan lebih dari satu perangkat
+// dianggap grup redundan.
+var DeviceMapping = map[string]map[string][]string{
+	"Timika": {
+		"Core Router":       {"IPCN_TMK_CR2-G1L", "IPCN_TMK_CR1-G1L"},
+		"Core Switch":       {"IPCN_TMK_CSW-G1L"},
+		"Management Router": {"IPCN_TMK_MR1-G1L", "IPCN_TMK_MR2-G1L"},
+		"Management Switch": {"IPCN_TMK_MSW-G1L"},
+		"Firewall":          {"IPCN_TMK_NGFW2-G1L", "IPCN_TMK_NGFW1-G1L"},
+		"Sandvine":          {"IPCN_TMK_DPI-G1L"},
+		"Server":            {"IPCN_TMK_SRV2-G1L"},
+	},
+	"Manokwari": {
+		"Core Router":       {"IPCN_MNK_CR2-G1K", "IPCN_MNK_CR1-G1K"},
+		"Core Switch":       {"IPCN_MNK_CSW-G1K"},
+		"Management Router": {"IPCN_MNK_MR1-G1K", "IPCN_MNK_MR2-G1K"},
+		"Management Switch": {"IPCN_MNK_MSW-G1K"},
+		"Firewall":          {"IPCN_MNK_NGFW1-G1K", "IPCN_MNK_NGFW2-G1K"},
+		"Sandvine":          {"IPCN_MNK_DPI-G1K"},
+	},
+	"Jayapura": {
+		"Core Router":       {"IPCN_JYP_G1G-CR2", "IPCN_JYP_G1G-CR1"},
+		"Core Switch":       {"IPCN_JYP_G1G-CSW2", "IPCN_JYP_G1G-CSW1"},
+		"Management Router": {"IPCN_JYP_G1G-MR2", "IPCN_JYP_G1G-MR1", "IPCN_JYP_G1G-MR3", "IPCN_JYP_G1G-MR4"},
+		"Management Switch": {"IPCN_JYP_G1G-MSW"},
+		"Firewall":          {"IPCN_JYP_G1G-NGFW2", "IPCN_JYP_G1G-NGFW1", "IPCN_JYP_G1G-NGFW3"},
+		"CHR Mikrotik":      {"IPCN_JYP_G1G-CICI2", "IPCN_JYP_G1G-CICI1"},
+		"Server":            {"IPCN_JYP_G1G-SRV01", "IPCN_JYP_G1G-SRV02"},
+	},
+}
+
+// DevicesForGateway mengembalikan kategori perangkat untuk gateway, tanpa
+// membedakan huruf besar/kecil ("JAYAPURA" dan "Jayapura" sama).
+func DevicesForGateway(gateway string) map[string][]string {
+	for name, categories := range DeviceMapping {
+		if strings.EqualFold(name, gateway) {
+			return categories
+		}
+	}
+	return nil
+}
+
+// IsUp menandakan statustext_ping perangkat menunjukkan perangkat hidup.
+func IsUp(status string) bool {
+	return strings.EqualFold(strings.TrimSpace(status), "up")
+}
+
+// IsDown menandakan status secara eksplisit "Down". Status selain Up dan Down
+// (Warning, Unknown, Paused, kosong) berarti kondisi tidak diketahui.
+func IsDown(status string) bool {
+	return strings.EqualFold(strings.TrimSpace(status), "down")
+}
@@ -0,0 +1,212 @@
+package ipcn
+
+import (
+	"bella/api"
+	configs "bella/config"
+	"bella/internal/notifier"
+	"bella/internal/state"
+	"bella/internal/types"
+	"bella/internal/wib"
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+const (
+	ImpactDegraded         = "DEGRADED"
+	ImpactServiceImpacting = "SERVICE_IMPACTING"
+
+	SeverityMajor    = "MAJOR"
+	SeverityCritical = "CRITICAL"
+)
+
+// Service memantau status ping perangkat IPCN satu gateway dari endpoint
+// sensor-status hub yang melayani gateway tersebut.
+type Service struct {
+	apiClient *api.APIClient
+	notifier  notifier.Notifier
+	state     *state.Manager
+	timeout   time.Duration
+	devices   map[string][]string
+	name      string
+}
+
+func NewService(apiClient *api.APIClient, notifier notifier.Notifier, stateMgr *state.Manager, name string, config *configs.AppConfig) *Service {
+	return &Service{
+		apiClient: apiClient,
+		notifier:  notifier,
+		state:     stateMgr,
+		timeout:   config.QueryTimeout,
+		devices:   DevicesForGateway(name),
+		name:      name,
+	}
+}
+
+func (s *Service) CheckAndAlert() {
+	slog.Info("Cron job terpicu, memulai pengecekan perangkat IPCN...", "gateway", s.name)
+	if len(s.devices) == 0 {
+		slog.Warn("Tidak ada pemetaan perangkat IPCN untuk gateway", "gateway", s.name)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	// Pengecekan terjadwal selalu mengambil data baru; hasilnya ikut mengisi cache untuk bot.
+	sensors, err := api.GetIpcnSensorStatus(api.WithForceRefresh(ctx), s.apiClient, "")
+	if err != nil {
+		slog.Error("Gagal mendapatkan sensor-status IPCN", "gateway", s.name, "error", err)
+		return
+	}
+
+	statuses := make(map[string]string, len(*sensors))
+	for _, sensor := range *sensors {
+		statuses[strings.TrimSpace(sensor.DeviceName)] = sensor.StatustextPing
+	}
+
+	now := wib.Now()
+	currentDown := make(map[string]types.IpcnDeviceDownAlert)
+	for category, members := range s.devices {
+		var downMembers []string
+		for _, member := range members {
+			// Status selain Up/Down (Warning, Unknown, kosong) tidak dianggap down.
+			if IsDown(statuses[member]) {
+				downMembers = append(downMembers, member)
+			}
+		}
+
+		impact, severity := evaluateCategory(len(downMembers), len(members))
+		for _, member := range downMembers {
+			currentDown[s.getAlertKey(member)] = types.IpcnDeviceDownAlert{
+				GatewayName: s.name,
+				Category:    category,
+				DeviceName:  member,
+				Status:      statuses[member],
+				Severity:    severity,
+				GroupDown:   len(downMembers),
+				GroupSize:   len(members),
+				Impact:      impact,
+				StartTime:   now,
+			}
+		}
+	}
+
+	previousAlerts := s.state.GetActiveAlerts()
+
+	downAlerts := make(map[string]types.IpcnDeviceDownAlert)
+	for key, alert := range currentDown {
+		previous, exists := previousAlerts[key]
+		if !exists {
+			slog.Info("Perangkat IPCN terdeteksi DOWN", "gateway", s.name, "device", alert.DeviceName, "severity", alert.Severity)
+			downAlerts[key] = alert
+			continue
+		}
+
+		previousDetails, _ := state.DetailsAs[types.IpcnDeviceDownAlert](previous)
+		if !previousDetails.StartTime.IsZero() {
+			alert.StartTime = previousDetails.StartTime
+		}
+		if previousDetails.Severity == alert.Severity {
+			continue
+		}
+		if alert.Severity != SeverityCritical {
+			// Penurunan severity tidak dinotifikasi, cukup perbarui state.
+			s.saveAlert(key, alert)
+			continue
+		}
+		slog.Warn("Severity perangkat IPCN dinaikkan, seluruh grup redundan down", "gateway", s.name, "device", alert.DeviceName, "category", alert.Category)
+		alert.Escalated = true
+		downAlerts[key] = alert
+	}
+
+	recoveredAlerts := make(map[string]types.IpcnDeviceUpAlert)
+	prefix := s.getAlertKey("")
+	for key, alert := range previousAlerts {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		if _, stillDown := currentDown[key]; stillDown {
+			continue
+		}
+		deviceName := strings.TrimPrefix(key, prefix)
+		if !IsUp(statuses[deviceName]) {
+			// Perangkat tidak ada di respons API atau statusnya tidak diketahui,
+			// status pulih belum bisa dipastikan.
+			continue
+		}
+
+		slog.Info("Perangkat IPCN terdeteksi PULIH", "gateway", s.name, "device", deviceName)
+		details, _ := state.DetailsAs[types.IpcnDeviceDownAlert](alert)
+		recoveredAlerts[key] = types.IpcnDeviceUpAlert{
+			GatewayName:  s.name,
+			Category:     categoryOf(s.devices, deviceName),
+			DeviceName:   deviceName,
+			RecoveryTime: now,
+			TimeDown:     details.StartTime,
+		}
+	}
+
+	// State baru diubah setelah notifikasi terkirim, sehingga notifikasi yang
+	// gagal dikirim ulang pada pengecekan berikutnya.
+	if len(downAlerts) > 0 {
+		alerts := make([]types.IpcnDeviceDownAlert, 0, len(downAlerts))
+		for _, alert := range downAlerts {
+			alerts = append(alerts, alert)
+		}
+		if err := s.notifier.SendIpcnDeviceDownAlert(alerts); err != nil {
+			slog.Error("Gagal mengirim notifikasi IPCN DOWN, akan dicoba lagi pada pengecekan berikutnya", "gateway", s.name, "error", err)
+		} else {
+			for key, alert := range downAlerts {
+				s.saveAlert(key, alert)
+			}
+		}
+	}
+	if len(recoveredAlerts) > 0 {
+		alerts := make([]types.IpcnDeviceUpAlert, 0, len(recoveredAlerts))
+		for _, alert := range recoveredAlerts {
+			alerts = append(alerts, alert)
+		}
+		if err := s.notifier.SendIpcnDeviceUpAlert(alerts); err != nil {
+			slog.Error("Gagal mengirim notifikasi IPCN UP, akan dicoba lagi pada pengecekan berikutnya", "gateway", s.name, "error", err)
+		} else {
+			for key := range recoveredAlerts {
+				s.state.RemoveAlertByKey(key)
+			}
+		}
+	}
+}
+
+// evaluateCategory menentukan dampak dan severity dari jumlah perangkat down
+// dalam satu kategori. Kehilangan semua anggota grup redundan berarti CRITICAL;
+// selain itu MAJOR.
+func evaluateCategory(down, size int) (string, string) {
+	if size > 1 && down == size {
+		return ImpactServiceImpacting, SeverityCritical
+	}
+	return ImpactDegraded, SeverityMajor
+}
+
+func (s *Service) saveAlert(key string, alert types.IpcnDeviceDownAlert) {
+	s.state.AddAlert(key, state.ActiveAlert{
+		Type:    "ipcn_down",
+		Gateway: s.name,
+		Details: alert,
+	})
+}
+
+func categoryOf(devices map[string][]string, deviceName string) string {
+	for category, members := range devices {
+		for _, member := range members {
+			if member == deviceName {
+				return category
+			}
+		}
+	}
+	return ""
+}
+
+func (s *Service) getAlertKey(deviceName string) string {
+	return fmt.Sprintf("ipcn_down_%s_%s", s.name, deviceName)
+}
@@ -0,0 +1,155 @@
+package ipcn
+
+import (
+	"bella/api"
+	"bella/internal/state"
+	"bella/internal/types"
+	"bella/internal/wib"
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+const (
+	TransitEventMainDown = "MAIN_DOWN"
+	TransitEventBothDown = "BOTH_DOWN"
+	TransitEventRestored = "RESTORED"
+
+	SeverityMinor = "MINOR"
+)
+
+// CheckTransitAndAlert memantau status IP transit main/backup dan NMS dari
+// endpoint ipcn/status hub gateway.
+func (s *Service) CheckTransitAndAlert() {
+	slog.Info("Cron job terpicu, memulai pengecekan IP transit dan NMS...", "gateway", s.name)
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	status, err := api.GetIpcnStatus(api.WithForceRefresh(ctx), s.apiClient)
+	if err != nil {
+		slog.Error("Gagal mendapatkan status IPCN", "gateway", s.name, "error", err)
+		return
+	}
+
+	now := wib.Now()
+	s.evaluateTransit(status.IpTransitMain.StatusText, status.IpTransitBackupStatus.StatusText, now)
+	s.evaluateNms(status.NmsStatus.StatusText, now)
+}
+
+// evaluateTransit mengirim event hanya saat kondisi transit berubah:
+// normal -> backup -> keduanya down, dan kembali ke main. Status selain Up
+// dan Down dianggap tidak diketahui dan tidak mengubah state.
+func (s *Service) evaluateTransit(mainStatus, backupStatus string, now time.Time) {
+	if !IsUp(mainStatus) && !IsDown(mainStatus) {
+		slog.Warn("Status IP transit main tidak diketahui, pengecekan dilewati", "gateway", s.name, "main", mainStatus)
+		return
+	}
+	if IsDown(mainStatus) && !IsUp(backupStatus) && !IsDown(backupStatus) {
+		slog.Warn("Status IP transit backup tidak diketahui, pengecekan dilewati", "gateway", s.name, "main", mainStatus, "backup", backupStatus)
+		return
+	}
+
+	key := fmt.Sprintf("iptx_transit_%s", s.name)
+	previous, exists := s.state.GetAlertByKey(key)
+	previousDetails, _ := state.DetailsAs[types.IpTransitAlert](previous)
+
+	alert := types.IpTransitAlert{
+		GatewayName:  s.name,
+		MainStatus:   mainStatus,
+		BackupStatus: backupStatus,
+		StartTime:    now,
+	}
+	if exists && !previousDetails.StartTime.IsZero() {
+		alert.StartTime = previousDetails.StartTime
+	}
+
+	switch {
+	case IsUp(mainStatus):
+		if !exists {
+			return
+		}
+		alert.Event = TransitEventRestored
+		alert.Severity = "INFO"
+		alert.RecoveryTime = now
+		slog.Info("IP transit kembali ke main", "gateway", s.name)
+	case IsUp(backupStatus):
+		if previousDetails.Event == TransitEventMainDown {
+			return
+		}
+		alert.Event = TransitEventMainDown
+		alert.Severity = SeverityMajor
+		slog.Warn("IP transit main down, berjalan di backup", "gateway", s.name, "main", mainStatus, "backup", backupStatus)
+	default:
+		if previousDetails.Event == TransitEventBothDown {
+			return
+		}
+		alert.Event = TransitEventBothDown
+		alert.Severity = SeverityCritical
+		slog.Error("IP transit main dan backup down", "gateway", s.name, "main", mainStatus, "backup", backupStatus)
+	}
+
+	// State baru diubah setelah notifikasi terkirim, sehingga event yang gagal
+	// dikirim diulang pada pengecekan berikutnya.
+	if err := s.notifier.SendIpTransitAlert(alert); err != nil {
+		slog.Error("Gagal mengirim notifikasi IP transit, akan dicoba lagi pada pengecekan berikutnya", "gateway", s.name, "event", alert.Event, "error", err)
+		return
+	}
+	if alert.Event == TransitEventRestored {
+		s.state.RemoveAlertByKey(key)
+		return
+	}
+	s.state.AddAlert(key, state.ActiveAlert{
+		Type:    "iptx_transit",
+		Gateway: s.name,
+		Details: alert,
+	})
+}
+
+func (s *Service) evaluateNms(nmsStatus string, now time.Time) {
+	if !IsUp(nmsStatus) && !IsDown(nmsStatus) {
+		if strings.TrimSpace(nmsStatus) != "" {
+			slog.Warn("Status NMS tidak diketahui, pengecekan dilewati", "gateway", s.name, "status", nmsStatus)
+		}
+		return
+	}
+
+	key := fmt.Sprintf("nms_down_%s", s.name)
+	previous, exists := s.state.GetAlertByKey(key)
+
+	if IsDown(nmsStatus) {
+		if exists {
+			return
+		}
+		alert := types.NmsDownAlert{
+			GatewayName: s.name,
+			Status:      nmsStatus,
+			Severity:    SeverityMinor,
+			StartTime:   now,
+		}
+		slog.Warn("NMS tidak dapat dijangkau", "gateway", s.name, "status", nmsStatus)
+		if err := s.notifier.SendNmsDownAlert(alert); err != nil {
+			slog.Error("Gagal mengirim notifikasi NMS DOWN, akan dicoba lagi pada pengecekan berikutnya", "gateway", s.name, "error", err)
+			return
+		}
+		s.state.AddAlert(key, state.ActiveAlert{
+			Type:    "nms_down",
+			Gateway: s.name,
+			Details: alert,
+		})
+		return
+	}
+
+	if !exists {
+		return
+	}
+	slog.Info("NMS kembali dapat dijangkau", "gateway", s.name)
+	previousDetails, _ := state.DetailsAs[types.NmsDownAlert](previous)
+	if err := s.notifier.SendNmsUpAlert(types.NmsUpAlert{GatewayName: s.name, RecoveryTime: now, TimeDown: previousDetails.StartTime}); err != nil {
+		slog.Error("Gagal mengirim notifikasi NMS UP, akan dicoba lagi pada pengecekan berikutnya", "gateway", s.name, "error", err)
+		return
+	}
+	s.state.RemoveAlertByKey(key)
+}
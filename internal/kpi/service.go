@@ -0,0 +1,196 @@
+package kpi
+
+import (
+	"bella/api"
+	configs "bella/config"
+	"bella/internal/notifier"
+	"bella/internal/state"
+	"bella/internal/types"
+	"bella/internal/wib"
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+const (
+	MetricCNBeacon    = "cn_beacon"
+	MetricBeamOffline = "beam_offline"
+	MetricUTDrop      = "online_ut_drop"
+)
+
+// Service memantau KPI level hub dari API G1x: CN beacon, jumlah beam offline,
+// dan penurunan online UT. Setiap metrik memiliki alert dan siklus state sendiri.
+type Service struct {
+	apiClient *api.APIClient
+	notifier  notifier.Notifier
+	state     *state.Manager
+	hub       string
+	timeout   time.Duration
+
+	beaconMin      float64
+	beamOfflineMax int
+	utDropPercent  float64
+	utDropWindow   time.Duration
+}
+
+func NewService(apiClient *api.APIClient, notifier notifier.Notifier, stateMgr *state.Manager, config *configs.AppConfig) *Service {
+	return &Service{
+		apiClient:      apiClient,
+		notifier:       notifier,
+		state:          stateMgr,
+		hub:            apiClient.Hub,
+		timeout:        config.QueryTimeout,
+		beaconMin:      config.CNBeaconMin,
+		beamOfflineMax: config.KPIBeamOfflineMax,
+		utDropPercent:  config.KPIUTDropPercent,
+		utDropWindow:   config.KPIUTDropWindow,
+	}
+}
+
+// reading adalah hasil evaluasi satu metrik. ok=false berarti data tidak
+// tersedia sehingga state alert metrik tersebut tidak diubah.
+type reading struct {
+	ok        bool
+	breached  bool
+	value     float64
+	threshold float64
+	baseline  float64
+}
+
+func (s *Service) CheckAndAlert() {
+	slog.Info("Cron job terpicu, memulai pengecekan KPI gateway...", "hub", s.hub)
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+	// Pengecekan terjadwal selalu mengambil data baru; hasilnya ikut mengisi cache untuk bot.
+	ctx = api.WithForceRefresh(ctx)
+
+	s.evaluate(MetricCNBeacon, s.checkBeacon(ctx))
+	s.evaluate(MetricBeamOffline, s.checkBeamOffline(ctx))
+	s.evaluate(MetricUTDrop, s.checkUTDrop(ctx))
+}
+
+func (s *Service) checkBeacon(ctx context.Context) reading {
+	beacon, err := api.GetCnBeacon(ctx, s.apiClient)
+	if err != nil {
+		slog.Error("Gagal mendapatkan CN beacon", "hub", s.hub, "error", err)
+		return reading{}
+	}
+	value := beacon.Data.Value
+	return reading{ok: true, breached: value < s.beaconMin, value: value, threshold: s.beaconMin}
+}
+
+func (s *Service) checkBeamOffline(ctx context.Context) reading {
+	beam, err := api.GetBeamTerminalStatus(ctx, s.apiClient)
+	if err != nil {
+		slog.Error("Gagal mendapatkan status beam", "hub", s.hub, "error", err)
+		return reading{}
+	}
+	offline := beam.Data.StatusCounts.Offline
+	return reading{ok: true, breached: offline > s.beamOfflineMax, value: float64(offline), threshold: float64(s.beamOfflineMax)}
+}
+
+// checkUTDrop membandingkan online UT terakhir dengan nilai tertinggi dalam
+// utDropWindow sebelum titik terakhir.
+func (s *Service) checkUTDrop(ctx context.Context) reading {
+	if s.utDropPercent <= 0 {
+		return reading{}
+	}
+	onlineUT, err := api.GetOnlineUT(ctx, s.apiClient)
+	if err != nil {
+		slog.Error("Gagal mendapatkan online UT", "hub", s.hub, "error", err)
+		return reading{}
+	}
+	if len(onlineUT.Data) == 0 {
+		slog.Warn("Data online UT kosong", "hub", s.hub)
+		return reading{}
+	}
+
+	latest := onlineUT.Data[len(onlineUT.Data)-1]
+	windowStart := latest.CreatedAt.Add(-s.utDropWindow)
+	baseline := latest.UtOnlineToa
+	for _, point := range onlineUT.Data {
+		if point.CreatedAt.Before(windowStart) || point.CreatedAt.After(latest.CreatedAt) {
+			continue
+		}
+		if point.UtOnlineToa > baseline {
+			baseline = point.UtOnlineToa
+		}
+	}
+
+	value := float64(latest.UtOnlineToa)
+	drop := 0.0
+	if baseline > 0 {
+		drop = (float64(baseline) - value) / float64(baseline) * 100
+	}
+	return reading{ok: true, breached: drop > s.utDropPercent, value: value, threshold: s.utDropPercent, baseline: float64(baseline)}
+}
+
+// evaluate membuka alert saat metrik pertama kali melewati batas dan
+// menutupnya saat metrik kembali normal. State baru diubah setelah notifikasi
+// terkirim, sehingga notifikasi yang gagal dicoba lagi pada pengecekan berikutnya.
+func (s *Service) evaluate(metric string, r reading) {
+	if !r.ok {
+		return
+	}
+	key := s.getAlertKey(metric)
+	previous, exists := s.state.GetAlertByKey(key)
+	previousDetails, _ := state.DetailsAs[types.GatewayKPIAlert](previous)
+	now := wib.Now()
+
+	if exists && metric == MetricUTDrop {
+		// Puncak sebelum penurunan bisa sudah keluar dari window, sehingga
+		// pemulihan diukur terhadap baseline saat alert dibuka.
+		if baseline := previousDetails.Baseline; baseline > 0 {
+			r.baseline = baseline
+			r.breached = (baseline-r.value)/baseline*100 > s.utDropPercent
+		}
+	}
+
+	if r.breached {
+		if exists {
+			return
+		}
+		alert := types.GatewayKPIAlert{
+			Hub:       s.hub,
+			Metric:    metric,
+			Value:     r.value,
+			Threshold: r.threshold,
+			Baseline:  r.baseline,
+			StartTime: now,
+		}
+		slog.Info("KPI gateway melewati batas", "hub", s.hub, "metric", metric, "value", r.value, "threshold", r.threshold)
+		if err := s.notifier.SendGatewayKPIAlert(alert); err != nil {
+			slog.Error("Gagal mengirim notifikasi KPI gateway", "hub", s.hub, "metric", metric, "error", err)
+			return
+		}
+		s.state.AddAlert(key, state.ActiveAlert{
+			Type:    "kpi_" + metric,
+			Gateway: s.hub,
+			Details: alert,
+		})
+		return
+	}
+
+	if !exists {
+		return
+	}
+	slog.Info("KPI gateway kembali normal", "hub", s.hub, "metric", metric, "value", r.value)
+	recovery := types.GatewayKPIUpAlert{
+		Hub:          s.hub,
+		Metric:       metric,
+		Value:        r.value,
+		RecoveryTime: now,
+		TimeDown:     previousDetails.StartTime,
+	}
+	if err := s.notifier.SendGatewayKPIUpAlert(recovery); err != nil {
+		slog.Error("Gagal mengirim notifikasi pemulihan KPI gateway", "hub", s.hub, "metric", metric, "error", err)
+		return
+	}
+	s.state.RemoveAlertByKey(key)
+}
+
+func (s *Service) getAlertKey(metric string) string {
+	return fmt.Sprintf("kpi_%s_%s", metric, s.hub)
+}
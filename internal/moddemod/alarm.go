@@ -0,0 +1,94 @@
+package moddemod
+
+import (
+	"bella/internal/state"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	DirectionEscalated   = "ESCALATED"
+	DirectionDeescalated = "DE-ESCALATED"
+	DirectionChanged     = "CHANGED"
+)
+
+// AlertDetails adalah data perangkat yang disimpan di state selama alert aktif.
+// Nama field sengaja sama dengan DeviceStatus agar state lama tetap terbaca.
+type AlertDetails struct {
+	DeviceName string
+	AlarmState string
+	Severity   string
+	UpdatedAt  time.Time
+}
+
+// alarmStateRank mengurutkan alarm_state dari yang paling ringan. Timeout
+// dianggap paling berat karena perangkat tidak lagi dapat dipantau.
+func alarmStateRank(alarmState string) int {
+	switch strings.ToLower(alarmState) {
+	case "minor":
+		return 1
+	case "major":
+		return 2
+	case "critical":
+		return 3
+	case "timeout":
+		return 4
+	default:
+		return 0
+	}
+}
+
+func SeverityFromAlarmState(alarmState string) string {
+	if alarmState == "" {
+		return "UNKNOWN"
+	}
+	return strings.ToUpper(alarmState)
+}
+
+// AlarmDirection menentukan arah perubahan alarm_state dari previous ke current.
+func AlarmDirection(previous, current string) string {
+	previousRank, currentRank := alarmStateRank(previous), alarmStateRank(current)
+	switch {
+	case currentRank > previousRank:
+		return DirectionEscalated
+	case currentRank < previousRank:
+		return DirectionDeescalated
+	default:
+		return DirectionChanged
+	}
+}
+
+func loadAlertDetails(alert state.ActiveAlert) (AlertDetails, error) {
+	switch details := alert.Details.(type) {
+	case AlertDetails:
+		return details, nil
+	case DeviceStatus:
+		return AlertDetails{
+			DeviceName: details.DeviceName,
+			AlarmState: details.AlarmState,
+			Severity:   SeverityFromAlarmState(details.AlarmState),
+			UpdatedAt:  details.UpdatedAt,
+		}, nil
+	case map[string]interface{}:
+		result := AlertDetails{}
+		result.DeviceName, _ = details["DeviceName"].(string)
+		result.AlarmState, _ = details["AlarmState"].(string)
+		result.Severity, _ = details["Severity"].(string)
+		if result.Severity == "" {
+			result.Severity = SeverityFromAlarmState(result.AlarmState)
+		}
+		updatedAtStr, ok := details["UpdatedAt"].(string)
+		if !ok {
+			return result, fmt.Errorf("field 'UpdatedAt' tidak ditemukan atau bukan string")
+		}
+		parsedTime, err := time.Parse(time.RFC3339Nano, updatedAtStr)
+		if err != nil {
+			return result, fmt.Errorf("gagal parsing 'UpdatedAt' %q: %w", updatedAtStr, err)
+		}
+		result.UpdatedAt = parsedTime
+		return result, nil
+	default:
+		return AlertDetails{}, fmt.Errorf("format 'Details' tidak terduga: %T", alert.Details)
+	}
+}
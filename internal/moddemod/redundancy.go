@@ -0,0 +1,69 @@
+package moddemod
+
+import (
+	configs "bella/config"
+	"strings"
+)
+
+const (
+	ImpactDegraded         = "DEGRADED"
+	ImpactServiceImpacting = "SERVICE_IMPACTING"
+)
+
+// GroupImpact menggambarkan kondisi grup redundansi tempat sebuah perangkat berada.
+type GroupImpact struct {
+	Group  string
+	Down   int
+	Size   int
+	Impact string
+}
+
+// evaluateRedundancy menghitung dampak setiap perangkat yang down terhadap grup
+// redundansinya. Perangkat di luar grup tidak dimasukkan ke hasil.
+func evaluateRedundancy(groups []configs.RedundancyGroup, deviceType string, downDevices map[string]DeviceStatus) map[string]GroupImpact {
+	impacts := make(map[string]GroupImpact)
+	for _, group := range groups {
+		if group.DeviceType != deviceType {
+			continue
+		}
+
+		down := 0
+		for _, member := range group.Members {
+			if _, isDown := downDevices[strings.TrimSpace(member)]; isDown {
+				down++
+			}
+		}
+		if down == 0 {
+			continue
+		}
+
+		impact := ImpactDegraded
+		if down == len(group.Members) {
+			impact = ImpactServiceImpacting
+		}
+		for _, member := range group.Members {
+			member = strings.TrimSpace(member)
+			if _, isDown := downDevices[member]; isDown {
+				impacts[member] = GroupImpact{
+					Group:  group.Name,
+					Down:   down,
+					Size:   len(group.Members),
+					Impact: impact,
+				}
+			}
+		}
+	}
+	return impacts
+}
+
+// severityFor menentukan severity alert. Untuk perangkat dalam grup redundansi,
+// kehilangan satu anggota berarti MAJOR dan kehilangan semua anggota berarti CRITICAL.
+func severityFor(alarmState string, impact GroupImpact, inGroup bool) string {
+	if !inGroup {
+		return SeverityFromAlarmState(alarmState)
+	}
+	if impact.Impact == ImpactServiceImpacting {
+		return "CRITICAL"
+	}
+	return "MAJOR"
+}
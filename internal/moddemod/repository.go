@@ -13,9 +13,29 @@ type DeviceStatus struct {
 	UpdatedAt  time.Time `gorm:"column:updated_at"`
 }
 
+// Device adalah satu baris inventaris modulator/demodulator beserta statusnya.
+type Device struct {
+	DeviceName string    `gorm:"column:device_name"`
+	Status     int       `gorm:"column:status"`
+	AlarmState string    `gorm:"column:alarm_state"`
+	UpdatedAt  time.Time `gorm:"column:updated_at"`
+}
+
+func (d Device) IsDown() bool {
+	return d.Status == 0
+}
+
 type Repository interface {
 	GetDownModulators() ([]DeviceStatus, error)
 	GetDownDemodulators() ([]DeviceStatus, error)
+	GetLatestModulatorUpdate() (time.Time, error)
+	GetLatestDemodulatorUpdate() (time.Time, error)
+	GetModulatorsByName(names []string) ([]DeviceStatus, error)
+	GetDemodulatorsByName(names []string) ([]DeviceStatus, error)
+	ListModulators() ([]Device, error)
+	ListDemodulators() ([]Device, error)
+	FindModulator(name string) (*Device, error)
+	FindDemodulator(name string) (*Device, error)
 }
 
 type gormRepository struct {
@@ -51,3 +71,99 @@ func (r *gormRepository) GetDownDemodulators() ([]DeviceStatus, error) {
 	}
 	return results, nil
 }
+
+func (r *gormRepository) GetLatestModulatorUpdate() (time.Time, error) {
+	return r.getLatestUpdate("modulators")
+}
+
+func (r *gormRepository) GetLatestDemodulatorUpdate() (time.Time, error) {
+	return r.getLatestUpdate("demodulators")
+}
+
+func (r *gormRepository) getLatestUpdate(table string) (time.Time, error) {
+	var result struct {
+		UpdatedAt *time.Time `gorm:"column:updated_at"`
+	}
+	err := r.db.Table(table).
+		Select("MAX(updated_at) AS updated_at").
+		Where("deleted_at IS NULL").
+		Scan(&result).Error
+	if err != nil {
+		return time.Time{}, fmt.Errorf("gagal query update terakhir %s: %w", table, err)
+	}
+	if result.UpdatedAt == nil {
+		return time.Time{}, nil
+	}
+	return *result.UpdatedAt, nil
+}
+
+func (r *gormRepository) GetModulatorsByName(names []string) ([]DeviceStatus, error) {
+	return r.getDevicesByName("modulators", names)
+}
+
+func (r *gormRepository) GetDemodulatorsByName(names []string) ([]DeviceStatus, error) {
+	return r.getDevicesByName("demodulators", names)
+}
+
+func (r *gormRepository) getDevicesByName(table string, names []string) ([]DeviceStatus, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	var results []DeviceStatus
+	err := r.db.Table(table).
+		Select("device_name, alarm_state, updated_at").
+		Where("device_name IN ?", names).
+		Where("deleted_at IS NULL").
+		Scan(&results).Error
+	if err != nil {
+		return nil, fmt.Errorf("gagal query %s berdasarkan nama: %w", table, err)
+	}
+	return results, nil
+}
+
+func (r *gormRepository) ListModulators() ([]Device, error) {
+	return r.listDevices("modulators")
+}
+
+func (r *gormRepository) ListDemodulators() ([]Device, error) {
+	return r.listDevices("demodulators")
+}
+
+func (r *gormRepository) listDevices(table string) ([]Device, error) {
+	var results []Device
+	err := r.db.Table(table).
+		Select("device_name, status, alarm_state, updated_at").
+		Where("deleted_at IS NULL").
+		Order("device_name").
+		Scan(&results).Error
+	if err != nil {
+		return nil, fmt.Errorf("gagal query inventaris %s: %w", table, err)
+	}
+	return results, nil
+}
+
+func (r *gormRepository) FindModulator(name string) (*Device, error) {
+	return r.findDevice("modulators", name)
+}
+
+func (r *gormRepository) FindDemodulator(name string) (*Device, error) {
+	return r.findDevice("demodulators", name)
+}
+
+// findDevice mencari perangkat berdasarkan nama (case-insensitive). Mengembalikan nil jika tidak ditemukan.
+func (r *gormRepository) findDevice(table string, name string) (*Device, error) {
+	var results []Device
+	err := r.db.Table(table).
+		Select("device_name, status, alarm_state, updated_at").
+		Where("LOWER(device_name) = LOWER(?)", name).
+		Where("deleted_at IS NULL").
+		Limit(1).
+		Scan(&results).Error
+	if err != nil {
+		return nil, fmt.Errorf("gagal query %s berdasarkan nama: %w", table, err)
+	}
+	if len(results) == 0 {
+		return nil, nil
+	}
+	return &results[0], nil
+}
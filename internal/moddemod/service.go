@@ -1,6 +1,9 @@
 package moddemod
 
 import (
+	configs "bella/config"
+	"bella/internal/freshness"
+	"bella/internal/history"
 	"bella/internal/notifier"
 	"bella/internal/state"
 	"bella/internal/types"
@@ -13,39 +16,32 @@ import (
 )
 
 type Service struct {
-	repo     Repository
-	notifier notifier.Notifier
-	state    *state.Manager
-	name     string
+	repo         Repository
+	notifier     notifier.Notifier
+	state        *state.Manager
+	freshness    *freshness.Checker
+	history      *history.Store
+	staleMaxAges map[string]time.Duration
+	groups       []configs.RedundancyGroup
+	name         string
 }
 
-func NewService(dbOne *gorm.DB, notifier notifier.Notifier, stateMgr *state.Manager, name string) *Service {
+func NewService(dbOne *gorm.DB, notifier notifier.Notifier, stateMgr *state.Manager, name string, config *configs.AppConfig, historyStore *history.Store) *Service {
 	return &Service{
-		repo:     NewGormRepository(dbOne),
-		notifier: notifier,
-		state:    stateMgr,
-		name:     name,
+		repo:      NewGormRepository(dbOne),
+		notifier:  notifier,
+		state:     stateMgr,
+		freshness: freshness.NewChecker(notifier, stateMgr),
+		history:   historyStore,
+		staleMaxAges: map[string]time.Duration{
+			"modulator":   config.StaleMaxAge("MODDEMOD", "modulators", name),
+			"demodulator": config.StaleMaxAge("MODDEMOD", "demodulators", name),
+		},
+		groups: config.RedundancyGroups[name],
+		name:   name,
 	}
 }
 
-func ParseWIBTimestamp(timeStr string) (time.Time, error) {
-	if timeStr == "" {
-		return time.Now(), nil
-	}
-	const layout = "2006-01-02T15:04:05"
-
-	loc, err := time.LoadLocation("Asia/Jakarta")
-	if err != nil {
-		return time.Time{}, err
-	}
-
-	if len(timeStr) < 19 {
-		return time.Time{}, fmt.Errorf("string waktu tidak valid: %s", timeStr)
-	}
-
-	return time.ParseInLocation(layout, timeStr[:19], loc)
-}
-
 func (s *Service) CheckAndAlert() {
 	slog.Info("Cron job terpicu, memulai pengecekan Modulator/Demodulator...", "gateway", s.name)
 	s.checkDevices("modulator")
@@ -54,8 +50,22 @@ func (s *Service) CheckAndAlert() {
 
 func (s *Service) checkDevices(deviceType string) {
 	var currentDownDevices []DeviceStatus
+	var latestUpdate time.Time
 	var err error
 
+	if deviceType == "modulator" {
+		latestUpdate, err = s.repo.GetLatestModulatorUpdate()
+	} else {
+		latestUpdate, err = s.repo.GetLatestDemodulatorUpdate()
+	}
+	if err != nil {
+		slog.Error("Gagal mendapatkan waktu update terakhir perangkat", "type", deviceType, "gateway", s.name, "error", err)
+		return
+	}
+	if !s.freshness.Evaluate(deviceType, s.name, latestUpdate, s.staleMaxAges[deviceType]) {
+		return
+	}
+
 	if deviceType == "modulator" {
 		currentDownDevices, err = s.repo.GetDownModulators()
 	} else {
@@ -68,15 +78,33 @@ func (s *Service) checkDevices(deviceType string) {
 
 	previousAlerts := s.state.GetActiveAlerts()
 
+	currentDownMap := make(map[string]DeviceStatus)
+	for _, dev := range currentDownDevices {
+		currentDownMap[dev.DeviceName] = dev
+	}
+	impacts := evaluateRedundancy(s.groups, deviceType, currentDownMap)
+
 	if len(currentDownDevices) > 0 {
 		slog.Info("Perangkat terdeteksi DOWN, mengirim notifikasi...", "gateway", s.name, "type", deviceType, "count", len(currentDownDevices))
 		downAlerts := []types.ModemDownAlert{}
 		for _, deviceStatus := range currentDownDevices {
+			impact, inGroup := impacts[deviceStatus.DeviceName]
+			startTime := deviceStatus.UpdatedAt
+			if previousAlert, exists := previousAlerts[s.getAlertKey(deviceStatus.DeviceName, deviceType)]; exists {
+				if details, err := loadAlertDetails(previousAlert); err == nil && !details.UpdatedAt.IsZero() {
+					startTime = details.UpdatedAt
+				}
+			}
 			downAlerts = append(downAlerts, types.ModemDownAlert{
 				GatewayName: s.name,
 				DeviceName:  deviceStatus.DeviceName,
 				AlarmState:  deviceStatus.AlarmState,
-				StartTime:   deviceStatus.UpdatedAt,
+				Severity:    severityFor(deviceStatus.AlarmState, impact, inGroup),
+				Group:       impact.Group,
+				GroupDown:   impact.Down,
+				GroupSize:   impact.Size,
+				Impact:      impact.Impact,
+				StartTime:   startTime,
 			})
 		}
 		if err := s.notifier.SendModemDownAlert(downAlerts, deviceType); err != nil {
@@ -84,63 +112,99 @@ func (s *Service) checkDevices(deviceType string) {
 		}
 	}
 
-	currentDownMap := make(map[string]DeviceStatus)
-	for _, dev := range currentDownDevices {
-		currentDownMap[dev.DeviceName] = dev
-	}
-
+	var alarmChanges []types.ModemAlarmChangeAlert
 	for _, deviceStatus := range currentDownDevices {
 		alertKey := s.getAlertKey(deviceStatus.DeviceName, deviceType)
-		if _, exists := previousAlerts[alertKey]; !exists {
+		impact, inGroup := impacts[deviceStatus.DeviceName]
+		severity := severityFor(deviceStatus.AlarmState, impact, inGroup)
+		previousAlert, exists := previousAlerts[alertKey]
+		if !exists {
 			slog.Info("Menambahkan perangkat DOWN baru ke state", "gateway", s.name, "type", deviceType, "device", deviceStatus.DeviceName)
 			s.state.AddAlert(alertKey, state.ActiveAlert{
 				Type:    deviceType,
 				Gateway: s.name,
-				Details: deviceStatus,
+				Details: AlertDetails{
+					DeviceName: deviceStatus.DeviceName,
+					AlarmState: deviceStatus.AlarmState,
+					Severity:   severity,
+					UpdatedAt:  deviceStatus.UpdatedAt,
+				},
+			})
+			s.recordTransition(deviceType, deviceStatus.DeviceName, history.EventDown, deviceStatus.AlarmState, deviceStatus.UpdatedAt)
+			continue
+		}
+
+		details, err := loadAlertDetails(previousAlert)
+		if err != nil {
+			slog.Warn("Gagal membaca detail alert perangkat dari state", "key", alertKey, "error", err)
+		}
+		alarmChanged := !strings.EqualFold(details.AlarmState, deviceStatus.AlarmState)
+		if !alarmChanged && details.Severity == severity {
+			continue
+		}
+
+		if alarmChanged && details.AlarmState != "" {
+			direction := AlarmDirection(details.AlarmState, deviceStatus.AlarmState)
+			slog.Info("Alarm state perangkat berubah", "gateway", s.name, "type", deviceType, "device", deviceStatus.DeviceName, "from", details.AlarmState, "to", deviceStatus.AlarmState, "direction", direction)
+			alarmChanges = append(alarmChanges, types.ModemAlarmChangeAlert{
+				GatewayName:   s.name,
+				DeviceName:    deviceStatus.DeviceName,
+				PreviousState: details.AlarmState,
+				CurrentState:  deviceStatus.AlarmState,
+				Direction:     direction,
+				ChangedAt:     deviceStatus.UpdatedAt,
+				StartTime:     details.UpdatedAt,
 			})
+			s.recordTransition(deviceType, deviceStatus.DeviceName, history.EventAlarmChange, deviceStatus.AlarmState, deviceStatus.UpdatedAt)
+		}
+
+		details.AlarmState = deviceStatus.AlarmState
+		details.Severity = severity
+		s.state.AddAlert(alertKey, state.ActiveAlert{
+			Type:    deviceType,
+			Gateway: s.name,
+			Details: details,
+		})
+	}
+
+	if len(alarmChanges) > 0 {
+		if err := s.notifier.SendModemAlarmChangeAlert(alarmChanges, deviceType); err != nil {
+			slog.Error("Gagal mengirim notifikasi perubahan alarm", "gateway", s.name, "type", deviceType, "error", err)
 		}
 	}
 
 	recoveredAlerts := []types.ModemUpAlert{}
+	recoveredKeys := make(map[string]string)
 	prefix := fmt.Sprintf("%s_%s_", deviceType, s.name)
 
-	for key, alertData := range previousAlerts {
+	for key := range previousAlerts {
 		if strings.HasPrefix(key, prefix) {
 			deviceName := strings.TrimPrefix(key, prefix)
 			if _, stillDown := currentDownMap[deviceName]; !stillDown {
+				recoveredKeys[deviceName] = key
+			}
+		}
+	}
 
-				var downTime time.Time
-
-				if detailsMap, ok := alertData.Details.(map[string]interface{}); ok {
-					if updatedAtStr, ok := detailsMap["UpdatedAt"].(string); ok {
-
-						parsedTime, err := ParseWIBTimestamp(updatedAtStr)
-						if err != nil {
-							slog.Error("Gagal parsing 'UpdatedAt' untuk modem pulih", "key", key, "error", err)
-							downTime = time.Now()
-						} else {
-							downTime = parsedTime
-						}
-					} else {
-						slog.Warn("Field 'UpdatedAt' tidak ditemukan atau bukan string", "key", key)
-						downTime = time.Now()
-					}
-				} else {
-					slog.Warn("Format 'Details' tidak terduga untuk modem pulih", "key", key)
-					downTime = time.Now()
-				}
+	recoveryTimes := s.getRecoveryTimes(deviceType, recoveredKeys, latestUpdate)
+	for deviceName, key := range recoveredKeys {
+		var downTime time.Time
+		if details, err := loadAlertDetails(previousAlerts[key]); err != nil {
+			slog.Warn("Gagal membaca waktu down untuk modem pulih", "key", key, "error", err)
+		} else {
+			downTime = details.UpdatedAt
+		}
 
-				slog.Info("Perangkat terdeteksi PULIH", "gateway", s.name, "type", deviceType, "device", deviceName)
+		slog.Info("Perangkat terdeteksi PULIH", "gateway", s.name, "type", deviceType, "device", deviceName)
 
-				recoveredAlerts = append(recoveredAlerts, types.ModemUpAlert{
-					GatewayName:  s.name,
-					DeviceName:   deviceName,
-					RecoveryTime: time.Now(),
-					TimeDown:     downTime,
-				})
-				s.state.RemoveAlertByKey(key)
-			}
-		}
+		recoveredAlerts = append(recoveredAlerts, types.ModemUpAlert{
+			GatewayName:  s.name,
+			DeviceName:   deviceName,
+			RecoveryTime: recoveryTimes[deviceName],
+			TimeDown:     downTime,
+		})
+		s.state.RemoveAlertByKey(key)
+		s.recordTransition(deviceType, deviceName, history.EventUp, "", recoveryTimes[deviceName])
 	}
 
 	if len(recoveredAlerts) > 0 {
@@ -150,6 +214,59 @@ func (s *Service) checkDevices(deviceType string) {
 	}
 }
 
+// getRecoveryTimes mengambil updated_at perangkat yang pulih sebagai waktu pemulihan.
+// Jika perangkat tidak lagi ditemukan, waktu update terakhir tabel dipakai.
+func (s *Service) getRecoveryTimes(deviceType string, recoveredKeys map[string]string, fallback time.Time) map[string]time.Time {
+	recoveryTimes := make(map[string]time.Time, len(recoveredKeys))
+	if len(recoveredKeys) == 0 {
+		return recoveryTimes
+	}
+
+	names := make([]string, 0, len(recoveredKeys))
+	for name := range recoveredKeys {
+		names = append(names, name)
+		recoveryTimes[name] = fallback
+	}
+
+	var devices []DeviceStatus
+	var err error
+	if deviceType == "modulator" {
+		devices, err = s.repo.GetModulatorsByName(names)
+	} else {
+		devices, err = s.repo.GetDemodulatorsByName(names)
+	}
+	if err != nil {
+		slog.Warn("Gagal mengambil waktu pemulihan perangkat, memakai waktu update terakhir", "type", deviceType, "gateway", s.name, "error", err)
+		return recoveryTimes
+	}
+	for _, device := range devices {
+		if !device.UpdatedAt.IsZero() {
+			recoveryTimes[device.DeviceName] = device.UpdatedAt
+		}
+	}
+	return recoveryTimes
+}
+
+// recordTransition mencatat perubahan status perangkat ke riwayat untuk perintah /device.
+func (s *Service) recordTransition(deviceType, deviceName, event, alarmState string, at time.Time) {
+	if s.history == nil {
+		return
+	}
+	status := 0
+	if event == history.EventUp {
+		status = 1
+	}
+	s.history.Record(history.Transition{
+		Gateway:    s.name,
+		DeviceType: deviceType,
+		DeviceName: deviceName,
+		Event:      event,
+		Status:     status,
+		AlarmState: alarmState,
+		Time:       at,
+	})
+}
+
 func (s *Service) getAlertKey(deviceName, deviceType string) string {
 	return fmt.Sprintf("%s_%s_%s", deviceType, s.name, deviceName)
 }
@@ -4,10 +4,12 @@ import (
 	"bella/internal/types"
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"log/slog"
 	"net/http"
+	"sort"
 	"strings"
 	"time"
 )
@@ -18,9 +20,23 @@ type Notifier interface {
 	DetermineFriendlyGatewayName(gatewayName string) string
 	SendPrtgTrafficDownAlert(traffic types.PRTGDownAlert) error
 	SendPrtgNIFDownAlert(traffic types.PRTGDownAlert) error
+	SendPrtgSensorDownAlert(alert types.PRTGDownAlert) error
 	SendPrtgUpAlert(alert types.PRTGUpAlert) error
+	SendPrtgDiscoveryAlert(added, removed []types.PRTGSensorChange) error
 	SendModemDownAlert(alerts []types.ModemDownAlert, deviceType string) error
 	SendModemUpAlert(alerts []types.ModemUpAlert, deviceType string) error
+	SendModemAlarmChangeAlert(alerts []types.ModemAlarmChangeAlert, deviceType string) error
+	SendStaleDataAlert(alert types.StaleDataAlert) error
+	SendStaleDataUpAlert(alert types.StaleDataUpAlert) error
+	SendTerminalDownAlert(alerts []types.TerminalDownAlert) error
+	SendTerminalUpAlert(alerts []types.TerminalUpAlert) error
+	SendIpcnDeviceDownAlert(alerts []types.IpcnDeviceDownAlert) error
+	SendIpcnDeviceUpAlert(alerts []types.IpcnDeviceUpAlert) error
+	SendGatewayKPIAlert(alert types.GatewayKPIAlert) error
+	SendGatewayKPIUpAlert(alert types.GatewayKPIUpAlert) error
+	SendIpTransitAlert(alert types.IpTransitAlert) error
+	SendNmsDownAlert(alert types.NmsDownAlert) error
+	SendNmsUpAlert(alert types.NmsUpAlert) error
 }
 
 type telegramNotifier struct {
@@ -58,6 +74,38 @@ func (t *telegramNotifier) sendMessage(text string) error {
 	return nil
 }
 
+// telegramMaxMessageLen adalah batas panjang pesan Telegram, dikurangi ruang
+// untuk penanda bagian "(1/3)".
+const telegramMaxMessageLen = 4096 - 32
+
+// sendChunked mengirim header diikuti baris-baris isi. Jika melebihi batas
+// Telegram, isi dipecah per baris menjadi beberapa pesan yang masing-masing
+// diawali header dan diberi penanda bagian. Semua bagian tetap dicoba dikirim
+// walaupun ada yang gagal.
+func (t *telegramNotifier) sendChunked(header string, lines []string) error {
+	var chunks []string
+	current := header
+	for _, line := range lines {
+		if len(current)+len(line) > telegramMaxMessageLen && current != header {
+			chunks = append(chunks, current)
+			current = header
+		}
+		current += line
+	}
+	chunks = append(chunks, current)
+
+	var errs []error
+	for i, chunk := range chunks {
+		if len(chunks) > 1 {
+			chunk += fmt.Sprintf("_\\(%d/%d\\)_", i+1, len(chunks))
+		}
+		if err := t.sendMessage(chunk); err != nil {
+			errs = append(errs, fmt.Errorf("bagian %d/%d: %w", i+1, len(chunks), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
 func escapeMarkdownV2(text string) string {
 	replacer := strings.NewReplacer("_", "\\_", "*", "\\*", "[", "\\[", "]", "\\]", "(", "\\(", ")", "\\)", "~", "\\~", "`", "\\`", ">", "\\>", "#", "\\#", "+", "\\+", "-", "\\-", "=", "\\=", "|", "\\|", "{", "\\{", "}", "\\}", ".", "\\.", "!", "\\!")
 	return replacer.Replace(text)
@@ -93,23 +141,30 @@ func AlarmStateToEmoji(state string) string {
 }
 
 func formatDuration(start time.Time) string {
-	const layout = "2006-01-02T15:04:05"
-
 	loc, err := time.LoadLocation("Asia/Jakarta")
 	if err != nil {
 		log.Printf("Gagal load timezone: %v", err)
 		return "Invalid Timezone"
 	}
 
-	now := time.Now().In(loc)
+	return formatDurationBetween(start, time.Now().In(loc))
+}
 
-	nowStr := now.Format(layout)
-	startStr := start.Format(layout)
+// formatDurationBetween menghitung durasi antara dua waktu berdasarkan jam dinding
+// masing-masing, sehingga timestamp database (WIB tanpa zona) dan waktu WIB sebanding.
+func formatDurationBetween(start, end time.Time) string {
+	const layout = "2006-01-02T15:04:05"
 
-	nowClean, _ := time.ParseInLocation(layout, nowStr, loc)
-	startClean, _ := time.ParseInLocation(layout, startStr, loc)
+	loc, err := time.LoadLocation("Asia/Jakarta")
+	if err != nil {
+		log.Printf("Gagal load timezone: %v", err)
+		return "Invalid Timezone"
+	}
 
-	duration := nowClean.Sub(startClean)
+	endClean, _ := time.ParseInLocation(layout, end.Format(layout), loc)
+	startClean, _ := time.ParseInLocation(layout, start.Format(layout), loc)
+
+	duration := endClean.Sub(startClean)
 
 	if duration < 0 {
 		duration = 0
@@ -163,6 +218,17 @@ func formatDuration(start time.Time) string {
 	return fmt.Sprintf("%d years", years)
 }
 
+// formatOutageDuration mengembalikan durasi gangguan, atau "N/A" jika waktu awal tidak diketahui.
+func formatOutageDuration(start, end time.Time) string {
+	if start.IsZero() {
+		return "N/A"
+	}
+	if end.IsZero() {
+		return formatDuration(start)
+	}
+	return formatDurationBetween(start, end)
+}
+
 func pluralSuffix(count int) string {
 	if count == 1 {
 		return ""
@@ -211,6 +277,8 @@ func (t *telegramNotifier) SendSatnetAlert(report types.GatewayReport) error {
 			durationStr = formatDuration(*satnet.StartIssue)
 		}
 
+		causeStr := formatSatnetCause(satnet)
+
 		fwdStr := escapeMarkdownV2(fmt.Sprintf("%.2f", satnet.FwdTp))
 		rtnStr := escapeMarkdownV2(fmt.Sprintf("%.2f", satnet.RtnTp))
 
@@ -220,6 +288,7 @@ func (t *telegramNotifier) SendSatnetAlert(report types.GatewayReport) error {
 				"   ├─ *RTN :* `%s kbps`\n"+
 				"   ├─ *Online UT :* `%s`\n"+
 				"   ├─ *Offline UT :* `%s`\n"+
+				"   ├─ *Cause :* `%s`\n"+
 				"   ├─ *Start :* `%s`\n"+
 				"   └─ *Duration :* `%s`\n\n",
 			escapeMarkdownV2(satnet.Name),
@@ -227,6 +296,7 @@ func (t *telegramNotifier) SendSatnetAlert(report types.GatewayReport) error {
 			rtnStr,
 			onlineStr,
 			offlineStr,
+			escapeMarkdownV2(causeStr),
 			startIssueStr,
 			escapeMarkdownV2(durationStr),
 		)
@@ -236,6 +306,32 @@ func (t *telegramNotifier) SendSatnetAlert(report types.GatewayReport) error {
 	return t.sendMessage(messageBuilder.String())
 }
 
+func formatSatnetCause(satnet types.SatnetDetail) string {
+	var cause string
+	switch satnet.Cause {
+	case "RAIN_FADE":
+		cause = "Probable rain fade"
+	case "EQUIPMENT":
+		cause = "Probable equipment/network fault"
+	case "UNKNOWN":
+		cause = "Unknown (insufficient Es/No and beacon data)"
+	default:
+		return "N/A"
+	}
+
+	var evidence []string
+	if satnet.EsnoBaseline != nil && satnet.EsnoCurrent != nil {
+		evidence = append(evidence, fmt.Sprintf("Es/No %.1f → %.1f dB", *satnet.EsnoBaseline, *satnet.EsnoCurrent))
+	}
+	if satnet.CnBeacon != nil {
+		evidence = append(evidence, fmt.Sprintf("beacon %.2f", *satnet.CnBeacon))
+	}
+	if len(evidence) > 0 {
+		cause += " (" + strings.Join(evidence, ", ") + ")"
+	}
+	return cause
+}
+
 func (t *telegramNotifier) SendSatnetUpAlert(alerts []types.SatnetUpAlert) error {
 	if len(alerts) == 0 {
 		return nil
@@ -258,7 +354,7 @@ func (t *telegramNotifier) SendSatnetUpAlert(alerts []types.SatnetUpAlert) error
 
 	for _, alert := range alerts {
 		timestamp := alert.RecoveryTime.Format("2006/01/02 15:04")
-		durationStr := formatDuration(alert.TimeDown)
+		durationStr := formatOutageDuration(alert.TimeDown, alert.RecoveryTime)
 
 		line := fmt.Sprintf("  🛟 *SATNET :* `%s`\n   ├─ *RECOVERED AT:* `%s`\n   └─ *DURATION:* `%s`\n\n",
 			escapeMarkdownV2(alert.SatnetName),
@@ -382,6 +478,55 @@ func (t *telegramNotifier) SendPrtgNIFDownAlert(nif types.PRTGDownAlert) error {
 	return t.sendMessage(messageBuilder.String())
 }
 
+// SendPrtgSensorDownAlert adalah template generik untuk sensor katalog PRTG
+// di luar kategori NIF dan IPTX.
+func (t *telegramNotifier) SendPrtgSensorDownAlert(alert types.PRTGDownAlert) error {
+	var messageBuilder strings.Builder
+
+	loc, err := time.LoadLocation("Asia/Jakarta")
+	if err != nil {
+		loc = time.Local
+	}
+
+	const timeLayout = "2006-01-02 15:04:05 MST"
+	formattedLastCheck := alert.LastCheck
+	if parsedCheckTime, err := time.ParseInLocation(timeLayout, alert.LastCheck, loc); err == nil {
+		formattedLastCheck = parsedCheckTime.Format("2006/01/02 15:04")
+	}
+
+	reason := alert.Reason
+	if reason == "" {
+		reason = "DOWN"
+	}
+
+	alertTitle := "🚨 *CRITICAL ALERT* 🚨"
+	eventLine := fmt.Sprintf("🗒 EVENT : *%s %s*", escapeMarkdownV2(alert.SensorType), escapeMarkdownV2(reason))
+	gatewayLine := fmt.Sprintf("📡 GATEWAY : *%s*", escapeMarkdownV2(alert.Location))
+	lastCheck := fmt.Sprintf("🕐 LAST CHECKED : *%s*", escapeMarkdownV2(formattedLastCheck))
+	separator := escapeMarkdownV2("━━━━━━━ ✦ ━━━━━━━")
+
+	header := fmt.Sprintf("%s\n\n%s\n%s\n%s\n%s\n\n", alertTitle, eventLine, gatewayLine, lastCheck, separator)
+	messageBuilder.WriteString(header)
+
+	durationStr := "N/A"
+	if !alert.DownSince.IsZero() {
+		durationStr = formatDuration(alert.DownSince)
+	}
+	lastMessage := alert.LastMessage
+	if lastMessage == "" {
+		lastMessage = "-"
+	}
+
+	messageBuilder.WriteString(fmt.Sprintf("   *DEVICE :* `%s`\n", escapeMarkdownV2(alert.DeviceName)))
+	messageBuilder.WriteString(fmt.Sprintf("   ├─ *SENSOR :* `%s`\n", escapeMarkdownV2(alert.SensorFullName)))
+	messageBuilder.WriteString(fmt.Sprintf("   ├─ *STATUS :* `%s`\n", escapeMarkdownV2(alert.Status)))
+	messageBuilder.WriteString(fmt.Sprintf("   ├─ *VALUE :* `%s` *\\(%s\\)*\n", escapeMarkdownV2(alert.Value), escapeMarkdownV2(reason)))
+	messageBuilder.WriteString(fmt.Sprintf("   ├─ *MESSAGE :* `%s`\n", escapeMarkdownV2(lastMessage)))
+	messageBuilder.WriteString(fmt.Sprintf("   └─ *DURATION :* `%s`\n\n", escapeMarkdownV2(durationStr)))
+
+	return t.sendMessage(messageBuilder.String())
+}
+
 func (t *telegramNotifier) SendPrtgUpAlert(alert types.PRTGUpAlert) error {
 	var messageBuilder strings.Builder
 
@@ -393,8 +538,7 @@ func (t *telegramNotifier) SendPrtgUpAlert(alert types.PRTGUpAlert) error {
 	header := fmt.Sprintf("%s\n\n%s\n%s\n%s\n\n", title, eventType, gatewayLine, separator)
 	messageBuilder.WriteString(header)
 
-	lastDown := alert.LastDown
-	durationStr := formatDuration(lastDown)
+	durationStr := formatOutageDuration(alert.LastDown, alert.RecoveryTime)
 
 	deviceLine := fmt.Sprintf("  🛟 *DEVICE :* `%s`\n", escapeMarkdownV2(alert.DeviceName))
 	sensorLine := fmt.Sprintf("   ├─ *SENSOR :* `%s`\n", escapeMarkdownV2(alert.SensorFullName))
@@ -409,6 +553,48 @@ func (t *telegramNotifier) SendPrtgUpAlert(alert types.PRTGUpAlert) error {
 	return t.sendMessage(messageBuilder.String())
 }
 
+func (t *telegramNotifier) SendPrtgDiscoveryAlert(added, removed []types.PRTGSensorChange) error {
+	if len(added) == 0 && len(removed) == 0 {
+		return nil
+	}
+	var messageBuilder strings.Builder
+
+	title := "🔭 *PRTG SENSOR DISCOVERY* 🔭"
+	eventLine := fmt.Sprintf("🗒 EVENT : *%d ADDED, %d REMOVED*", len(added), len(removed))
+	separator := escapeMarkdownV2("━━━━━━━ ✦ ━━━━━━━")
+	messageBuilder.WriteString(fmt.Sprintf("%s\n\n%s\n%s\n\n", title, eventLine, separator))
+
+	writeChanges := func(label string, changes []types.PRTGSensorChange) {
+		if len(changes) == 0 {
+			return
+		}
+		messageBuilder.WriteString(fmt.Sprintf("   *%s :*\n", label))
+		for i, change := range changes {
+			branch := "├─"
+			if i == len(changes)-1 {
+				branch = "└─"
+			}
+			alertNote := ""
+			if change.AlertClosed {
+				alertNote = " ⚠️ _active alert closed_"
+			}
+			messageBuilder.WriteString(fmt.Sprintf("   %s `%s` %s \\(%s, %s\\)%s\n",
+				branch,
+				escapeMarkdownV2(change.ID),
+				escapeMarkdownV2(change.Name),
+				escapeMarkdownV2(change.Category),
+				escapeMarkdownV2(change.Gateway),
+				alertNote,
+			))
+		}
+		messageBuilder.WriteString("\n")
+	}
+	writeChanges("ADDED", added)
+	writeChanges("REMOVED", removed)
+
+	return t.sendMessage(messageBuilder.String())
+}
+
 func (t *telegramNotifier) SendModemDownAlert(alerts []types.ModemDownAlert, deviceType string) error {
 	if len(alerts) == 0 {
 		return nil
@@ -419,6 +605,12 @@ func (t *telegramNotifier) SendModemDownAlert(alerts []types.ModemDownAlert, dev
 	deviceTypeUpper := strings.ToUpper(deviceType)
 
 	alertTitle := "🚨 *ALARM ALERT* 🚨"
+	for _, alert := range alerts {
+		if alert.Impact == "SERVICE_IMPACTING" {
+			alertTitle = "🚨 *CRITICAL ALERT \\- SERVICE IMPACTING* 🚨"
+			break
+		}
+	}
 	eventLine := fmt.Sprintf("🗒 EVENT : *%d %s%s ALARM ALERT*", count, escapeMarkdownV2(deviceTypeUpper), escapeMarkdownV2(pluralSuffix(count)))
 	gatewayLine := fmt.Sprintf("📡 GATEWAY : *%s*", escapeMarkdownV2(friendlyGatewayName))
 	header := fmt.Sprintf("%s\n\n%s\n%s\n%s\n\n", alertTitle, eventLine, gatewayLine, escapeMarkdownV2("━━━━━━━ ✦ ━━━━━━━"))
@@ -436,15 +628,34 @@ func (t *telegramNotifier) SendModemDownAlert(alerts []types.ModemDownAlert, dev
 
 		emoji := AlarmStateToEmoji(alarmState)
 
+		var redundancyLines string
+		if alert.Group != "" {
+			impact := "DEGRADED"
+			if alert.Impact == "SERVICE_IMPACTING" {
+				impact = "SERVICE IMPACTING"
+			}
+			redundancyLines = fmt.Sprintf(
+				"   ├─ *GROUP :* `%s` \\(%d/%d down\\)\n"+
+					"   ├─ *IMPACT :* `%s` \\(%s\\)\n",
+				escapeMarkdownV2(alert.Group),
+				alert.GroupDown,
+				alert.GroupSize,
+				escapeMarkdownV2(impact),
+				escapeMarkdownV2(alert.Severity),
+			)
+		}
+
 		info := fmt.Sprintf(
 			"  %s *DEVICE :* `%s`\n"+
 				"   ├─ *ALARM STATE :* `%s`\n"+
+				"%s"+
 				"   ├─ *START :* `%s`\n"+
 				"   └─ *DURATION :* `%s`\n\n",
 
 			escapeMarkdownV2(emoji),
 			escapeMarkdownV2(alert.DeviceName),
 			escapeMarkdownV2(alarmState),
+			redundancyLines,
 			escapeMarkdownV2(startTime),
 			escapeMarkdownV2(durationStr),
 		)
@@ -476,9 +687,527 @@ func (t *telegramNotifier) SendModemUpAlert(alerts []types.ModemUpAlert, deviceT
 				"   └─ *DURATION :* `%s`\n\n",
 			escapeMarkdownV2(alert.DeviceName),
 			escapeMarkdownV2(recoveryTime),
-			escapeMarkdownV2(formatDuration(alert.TimeDown)),
+			escapeMarkdownV2(formatOutageDuration(alert.TimeDown, alert.RecoveryTime)),
+		)
+		messageBuilder.WriteString(info)
+	}
+	return t.sendMessage(messageBuilder.String())
+}
+
+func (t *telegramNotifier) SendModemAlarmChangeAlert(alerts []types.ModemAlarmChangeAlert, deviceType string) error {
+	if len(alerts) == 0 {
+		return nil
+	}
+	var messageBuilder strings.Builder
+	friendlyGatewayName := t.DetermineFriendlyGatewayName(alerts[0].GatewayName)
+	count := len(alerts)
+	deviceTypeUpper := strings.ToUpper(deviceType)
+
+	escalated := 0
+	for _, alert := range alerts {
+		if alert.Direction == "ESCALATED" {
+			escalated++
+		}
+	}
+
+	title := "📈 *ALARM ESCALATION* 📈"
+	if escalated == 0 {
+		title = "📉 *ALARM DE\\-ESCALATION* 📉"
+	}
+	eventLine := fmt.Sprintf("🗒 EVENT : *%d %s%s ALARM STATE CHANGED*", count, escapeMarkdownV2(deviceTypeUpper), escapeMarkdownV2(pluralSuffix(count)))
+	gatewayLine := fmt.Sprintf("📡 GATEWAY : *%s*", escapeMarkdownV2(friendlyGatewayName))
+	header := fmt.Sprintf("%s\n\n%s\n%s\n%s\n\n", title, eventLine, gatewayLine, escapeMarkdownV2("━━━━━━━ ✦ ━━━━━━━"))
+	messageBuilder.WriteString(header)
+
+	for _, alert := range alerts {
+		arrow := "⬆️"
+		if alert.Direction == "DE-ESCALATED" {
+			arrow = "⬇️"
+		} else if alert.Direction != "ESCALATED" {
+			arrow = "↔️"
+		}
+
+		durationStr := "N/A"
+		if !alert.StartTime.IsZero() {
+			durationStr = formatDuration(alert.StartTime)
+		}
+
+		info := fmt.Sprintf(
+			"  %s *DEVICE :* `%s`\n"+
+				"   ├─ *%s :* `%s` → `%s`\n"+
+				"   ├─ *CHANGED AT :* `%s`\n"+
+				"   └─ *ALARM DURATION :* `%s`\n\n",
+			arrow,
+			escapeMarkdownV2(alert.DeviceName),
+			escapeMarkdownV2(alert.Direction),
+			escapeMarkdownV2(alert.PreviousState),
+			escapeMarkdownV2(alert.CurrentState),
+			escapeMarkdownV2(alert.ChangedAt.Format("2006/01/02 15:04")),
+			escapeMarkdownV2(durationStr),
+		)
+		messageBuilder.WriteString(info)
+	}
+	return t.sendMessage(messageBuilder.String())
+}
+
+func (t *telegramNotifier) SendStaleDataAlert(alert types.StaleDataAlert) error {
+	var messageBuilder strings.Builder
+	friendlyGatewayName := t.DetermineFriendlyGatewayName(alert.GatewayName)
+
+	alertTitle := "⚠️ *DATA STALE ALERT* ⚠️"
+	eventLine := "🗒 EVENT : *MONITORING BLIND*"
+	gatewayLine := fmt.Sprintf("📡 GATEWAY : *%s*", escapeMarkdownV2(friendlyGatewayName))
+	sourceLine := fmt.Sprintf("🗄 SOURCE : *%s*", escapeMarkdownV2(strings.ToUpper(alert.Source)))
+	header := fmt.Sprintf("%s\n\n%s\n%s\n%s\n%s\n\n", alertTitle, eventLine, gatewayLine, sourceLine, escapeMarkdownV2("━━━━━━━ ✦ ━━━━━━━"))
+	messageBuilder.WriteString(header)
+
+	lastDataStr := "N/A"
+	ageStr := "N/A"
+	if !alert.LastData.IsZero() {
+		lastDataStr = alert.LastData.Format("2006/01/02 15:04")
+		ageStr = formatDuration(alert.LastData)
+	}
+
+	info := fmt.Sprintf(
+		"   ├─ *LAST DATA :* `%s`\n"+
+			"   ├─ *AGE :* `%s`\n"+
+			"   └─ *MAX AGE :* `%s`\n\n",
+		escapeMarkdownV2(lastDataStr),
+		escapeMarkdownV2(ageStr),
+		escapeMarkdownV2(alert.MaxAge.String()),
+	)
+	messageBuilder.WriteString(info)
+	messageBuilder.WriteString(escapeMarkdownV2("Alert dari sumber ini tidak dapat dipercaya sampai data kembali diperbarui."))
+
+	return t.sendMessage(messageBuilder.String())
+}
+
+func (t *telegramNotifier) SendStaleDataUpAlert(alert types.StaleDataUpAlert) error {
+	var messageBuilder strings.Builder
+	friendlyGatewayName := t.DetermineFriendlyGatewayName(alert.GatewayName)
+
+	title := "🌟 *RECOVERY INFO* 🌟"
+	eventLine := "🗒 EVENT : *DATA FRESH AGAIN*"
+	gatewayLine := fmt.Sprintf("📡 GATEWAY : *%s*", escapeMarkdownV2(friendlyGatewayName))
+	sourceLine := fmt.Sprintf("🗄 SOURCE : *%s*", escapeMarkdownV2(strings.ToUpper(alert.Source)))
+	header := fmt.Sprintf("%s\n\n%s\n%s\n%s\n%s\n\n", title, eventLine, gatewayLine, sourceLine, escapeMarkdownV2("━━━━━━━ ✦ ━━━━━━━"))
+	messageBuilder.WriteString(header)
+
+	blindStr := formatOutageDuration(alert.StaleSince, alert.RecoveryTime)
+
+	info := fmt.Sprintf(
+		"   ├─ *LATEST DATA :* `%s`\n"+
+			"   └─ *BLIND FOR :* `%s`\n",
+		escapeMarkdownV2(alert.RecoveryTime.Format("2006/01/02 15:04")),
+		escapeMarkdownV2(blindStr),
+	)
+	messageBuilder.WriteString(info)
+
+	return t.sendMessage(messageBuilder.String())
+}
+
+func terminalConditionEmoji(condition string) string {
+	if condition == "offline" {
+		return "🔴"
+	}
+	return "🟠"
+}
+
+func (t *telegramNotifier) SendTerminalDownAlert(alerts []types.TerminalDownAlert) error {
+	if len(alerts) == 0 {
+		return nil
+	}
+
+	friendlyGatewayName := t.DetermineFriendlyGatewayName(alerts[0].GatewayName)
+
+	offlineCount, degradedCount := 0, 0
+	bySatnet := make(map[string][]types.TerminalDownAlert)
+	for _, alert := range alerts {
+		if alert.Condition == "offline" {
+			offlineCount++
+		} else {
+			degradedCount++
+		}
+		bySatnet[alert.SatnetName] = append(bySatnet[alert.SatnetName], alert)
+	}
+
+	alertTitle := "🚨 *UT ALERT* 🚨"
+	eventLine := fmt.Sprintf("🗒 EVENT : *%d UT OFFLINE, %d UT DEGRADED*", offlineCount, degradedCount)
+	gatewayLine := fmt.Sprintf("📡 GATEWAY : *%s*", escapeMarkdownV2(friendlyGatewayName))
+	header := fmt.Sprintf("%s\n\n%s\n%s\n%s\n\n", alertTitle, eventLine, gatewayLine, escapeMarkdownV2("━━━━━━━ ✦ ━━━━━━━"))
+
+	var lines []string
+	satnetNames := make([]string, 0, len(bySatnet))
+	for name := range bySatnet {
+		satnetNames = append(satnetNames, name)
+	}
+	sort.Strings(satnetNames)
+
+	for _, satnetName := range satnetNames {
+		terminals := bySatnet[satnetName]
+		sort.Slice(terminals, func(i, j int) bool { return terminals[i].TerminalName < terminals[j].TerminalName })
+
+		lines = append(lines, fmt.Sprintf("   *SATNET:* %s\n", escapeMarkdownV2(satnetName)))
+		for i, alert := range terminals {
+			connector := "├"
+			if i == len(terminals)-1 {
+				connector = "└"
+			}
+			esnoStr := "N/A"
+			if alert.EsnoAvg != nil {
+				esnoStr = fmt.Sprintf("%.2f dB", *alert.EsnoAvg)
+			}
+			line := fmt.Sprintf("   %s─ %s `%s` *%s* \\(Es/No `%s`, since `%s`\\)\n",
+				connector,
+				terminalConditionEmoji(alert.Condition),
+				escapeMarkdownV2(alert.TerminalName),
+				escapeMarkdownV2(strings.ToUpper(alert.Condition)),
+				escapeMarkdownV2(esnoStr),
+				escapeMarkdownV2(alert.StartTime.Format("2006/01/02 15:04")),
+			)
+			lines = append(lines, line)
+		}
+		lines = append(lines, "\n")
+	}
+
+	return t.sendChunked(header, lines)
+}
+
+func (t *telegramNotifier) SendTerminalUpAlert(alerts []types.TerminalUpAlert) error {
+	if len(alerts) == 0 {
+		return nil
+	}
+
+	friendlyGatewayName := t.DetermineFriendlyGatewayName(alerts[0].GatewayName)
+	count := len(alerts)
+
+	bySatnet := make(map[string][]types.TerminalUpAlert)
+	for _, alert := range alerts {
+		bySatnet[alert.SatnetName] = append(bySatnet[alert.SatnetName], alert)
+	}
+
+	title := "🌟 *RECOVERY INFO* 🌟"
+	eventLine := fmt.Sprintf("🗒 EVENT : *%d UT RECOVERED*", count)
+	gatewayLine := fmt.Sprintf("📡 GATEWAY : *%s*", escapeMarkdownV2(friendlyGatewayName))
+	header := fmt.Sprintf("%s\n\n%s\n%s\n%s\n\n", title, eventLine, gatewayLine, escapeMarkdownV2("━━━━━━━ ✦ ━━━━━━━"))
+
+	var lines []string
+	satnetNames := make([]string, 0, len(bySatnet))
+	for name := range bySatnet {
+		satnetNames = append(satnetNames, name)
+	}
+	sort.Strings(satnetNames)
+
+	for _, satnetName := range satnetNames {
+		terminals := bySatnet[satnetName]
+		sort.Slice(terminals, func(i, j int) bool { return terminals[i].TerminalName < terminals[j].TerminalName })
+
+		lines = append(lines, fmt.Sprintf("  🛟 *SATNET :* `%s`\n", escapeMarkdownV2(satnetName)))
+		for i, alert := range terminals {
+			connector := "├"
+			if i == len(terminals)-1 {
+				connector = "└"
+			}
+			durationStr := formatOutageDuration(alert.TimeDown, alert.RecoveryTime)
+			line := fmt.Sprintf("   %s─ `%s` %s *RECOVERED AT:* `%s` *DURATION:* `%s`\n",
+				connector,
+				escapeMarkdownV2(alert.TerminalName),
+				escapeMarkdownV2(strings.ToUpper(alert.Condition)),
+				escapeMarkdownV2(alert.RecoveryTime.Format("2006/01/02 15:04")),
+				escapeMarkdownV2(durationStr),
+			)
+			lines = append(lines, line)
+		}
+		lines = append(lines, "\n")
+	}
+
+	return t.sendChunked(header, lines)
+}
+
+func (t *telegramNotifier) SendIpcnDeviceDownAlert(alerts []types.IpcnDeviceDownAlert) error {
+	if len(alerts) == 0 {
+		return nil
+	}
+	var messageBuilder strings.Builder
+	friendlyGatewayName := t.DetermineFriendlyGatewayName(alerts[0].GatewayName)
+	count := len(alerts)
+
+	alertTitle := "🚨 *IPCN ALERT* 🚨"
+	for _, alert := range alerts {
+		if alert.Impact == "SERVICE_IMPACTING" {
+			alertTitle = "🚨 *CRITICAL ALERT \\- SERVICE IMPACTING* 🚨"
+			break
+		}
+	}
+	eventLine := fmt.Sprintf("🗒 EVENT : *%d IPCN DEVICE%s PING DOWN*", count, escapeMarkdownV2(pluralSuffix(count)))
+	gatewayLine := fmt.Sprintf("📡 GATEWAY : *%s*", escapeMarkdownV2(friendlyGatewayName))
+	header := fmt.Sprintf("%s\n\n%s\n%s\n%s\n\n", alertTitle, eventLine, gatewayLine, escapeMarkdownV2("━━━━━━━ ✦ ━━━━━━━"))
+	messageBuilder.WriteString(header)
+
+	sort.Slice(alerts, func(i, j int) bool {
+		if alerts[i].Category != alerts[j].Category {
+			return alerts[i].Category < alerts[j].Category
+		}
+		return alerts[i].DeviceName < alerts[j].DeviceName
+	})
+
+	for _, alert := range alerts {
+		emoji := "🔴"
+		severity := alert.Severity
+		if alert.Escalated {
+			emoji = "⏫"
+			severity += " (ESCALATED)"
+		}
+		status := alert.Status
+		if status == "" {
+			status = "Unknown"
+		}
+
+		var groupLine string
+		if alert.GroupSize > 1 {
+			impact := "DEGRADED"
+			if alert.Impact == "SERVICE_IMPACTING" {
+				impact = "SERVICE IMPACTING"
+			}
+			groupLine = fmt.Sprintf("   ├─ *GROUP :* `%s` \\(%d/%d down, %s\\)\n",
+				escapeMarkdownV2(alert.Category),
+				alert.GroupDown,
+				alert.GroupSize,
+				escapeMarkdownV2(impact),
+			)
+		} else {
+			groupLine = fmt.Sprintf("   ├─ *CATEGORY :* `%s`\n", escapeMarkdownV2(alert.Category))
+		}
+
+		info := fmt.Sprintf(
+			"  %s *DEVICE :* `%s`\n"+
+				"   ├─ *PING :* `%s`\n"+
+				"%s"+
+				"   ├─ *SEVERITY :* `%s`\n"+
+				"   ├─ *START :* `%s`\n"+
+				"   └─ *DURATION :* `%s`\n\n",
+			emoji,
+			escapeMarkdownV2(alert.DeviceName),
+			escapeMarkdownV2(status),
+			groupLine,
+			escapeMarkdownV2(severity),
+			escapeMarkdownV2(alert.StartTime.Format("2006/01/02 15:04")),
+			escapeMarkdownV2(formatDuration(alert.StartTime)),
 		)
 		messageBuilder.WriteString(info)
 	}
 	return t.sendMessage(messageBuilder.String())
 }
+
+func (t *telegramNotifier) SendIpcnDeviceUpAlert(alerts []types.IpcnDeviceUpAlert) error {
+	if len(alerts) == 0 {
+		return nil
+	}
+	var messageBuilder strings.Builder
+	friendlyGatewayName := t.DetermineFriendlyGatewayName(alerts[0].GatewayName)
+	count := len(alerts)
+
+	title := "🌟 *RECOVERY INFO* 🌟"
+	eventLine := fmt.Sprintf("🗒 EVENT : *%d IPCN DEVICE%s RECOVERED*", count, escapeMarkdownV2(pluralSuffix(count)))
+	gatewayLine := fmt.Sprintf("📡 GATEWAY : *%s*", escapeMarkdownV2(friendlyGatewayName))
+	header := fmt.Sprintf("%s\n\n%s\n%s\n%s\n\n", title, eventLine, gatewayLine, escapeMarkdownV2("━━━━━━━ ✦ ━━━━━━━"))
+	messageBuilder.WriteString(header)
+
+	for _, alert := range alerts {
+		info := fmt.Sprintf(
+			"  🛟 *DEVICE :* `%s`\n"+
+				"   ├─ *CATEGORY :* `%s`\n"+
+				"   ├─ *RECOVERED AT :* `%s`\n"+
+				"   └─ *DURATION :* `%s`\n\n",
+			escapeMarkdownV2(alert.DeviceName),
+			escapeMarkdownV2(alert.Category),
+			escapeMarkdownV2(alert.RecoveryTime.Format("2006/01/02 15:04")),
+			escapeMarkdownV2(formatOutageDuration(alert.TimeDown, alert.RecoveryTime)),
+		)
+		messageBuilder.WriteString(info)
+	}
+	return t.sendMessage(messageBuilder.String())
+}
+
+// kpiMetricInfo mengembalikan judul event dan format nilai untuk metrik KPI gateway.
+func kpiMetricInfo(metric string) (string, string) {
+	switch metric {
+	case "cn_beacon":
+		return "CN BEACON LOW", "%.2f dB"
+	case "beam_offline":
+		return "BEAM OFFLINE", "%.0f beam"
+	case "online_ut_drop":
+		return "ONLINE UT DROP", "%.0f UT"
+	default:
+		return strings.ToUpper(metric), "%.2f"
+	}
+}
+
+func (t *telegramNotifier) SendGatewayKPIAlert(alert types.GatewayKPIAlert) error {
+	var messageBuilder strings.Builder
+	event, valueFormat := kpiMetricInfo(alert.Metric)
+
+	alertTitle := "🚨 *GATEWAY KPI ALERT* 🚨"
+	eventLine := fmt.Sprintf("🗒 EVENT : *%s*", escapeMarkdownV2(event))
+	hubLine := fmt.Sprintf("📡 HUB : *%s*", escapeMarkdownV2(alert.Hub))
+	header := fmt.Sprintf("%s\n\n%s\n%s\n%s\n\n", alertTitle, eventLine, hubLine, escapeMarkdownV2("━━━━━━━ ✦ ━━━━━━━"))
+	messageBuilder.WriteString(header)
+
+	var detailLines string
+	switch alert.Metric {
+	case "online_ut_drop":
+		detailLines = fmt.Sprintf(
+			"   ├─ *BASELINE :* `%s`\n"+
+				"   ├─ *DROP :* `%s`\n"+
+				"   ├─ *THRESHOLD :* `%s`\n",
+			escapeMarkdownV2(fmt.Sprintf(valueFormat, alert.Baseline)),
+			escapeMarkdownV2(fmt.Sprintf("%.1f%%", dropPercent(alert.Baseline, alert.Value))),
+			escapeMarkdownV2(fmt.Sprintf("%.1f%%", alert.Threshold)),
+		)
+	case "cn_beacon":
+		detailLines = fmt.Sprintf("   ├─ *MIN :* `%s`\n", escapeMarkdownV2(fmt.Sprintf(valueFormat, alert.Threshold)))
+	default:
+		detailLines = fmt.Sprintf("   ├─ *MAX :* `%s`\n", escapeMarkdownV2(fmt.Sprintf(valueFormat, alert.Threshold)))
+	}
+
+	info := fmt.Sprintf(
+		"   ┌─ *VALUE :* `%s`\n"+
+			"%s"+
+			"   ├─ *START :* `%s`\n"+
+			"   └─ *DURATION :* `%s`\n",
+		escapeMarkdownV2(fmt.Sprintf(valueFormat, alert.Value)),
+		detailLines,
+		escapeMarkdownV2(alert.StartTime.Format("2006/01/02 15:04")),
+		escapeMarkdownV2(formatDuration(alert.StartTime)),
+	)
+	messageBuilder.WriteString(info)
+
+	return t.sendMessage(messageBuilder.String())
+}
+
+func (t *telegramNotifier) SendGatewayKPIUpAlert(alert types.GatewayKPIUpAlert) error {
+	var messageBuilder strings.Builder
+	event, valueFormat := kpiMetricInfo(alert.Metric)
+
+	title := "🌟 *RECOVERY INFO* 🌟"
+	eventLine := fmt.Sprintf("🗒 EVENT : *%s RECOVERED*", escapeMarkdownV2(event))
+	hubLine := fmt.Sprintf("📡 HUB : *%s*", escapeMarkdownV2(alert.Hub))
+	header := fmt.Sprintf("%s\n\n%s\n%s\n%s\n\n", title, eventLine, hubLine, escapeMarkdownV2("━━━━━━━ ✦ ━━━━━━━"))
+	messageBuilder.WriteString(header)
+
+	info := fmt.Sprintf(
+		"   ├─ *VALUE :* `%s`\n"+
+			"   ├─ *RECOVERED AT :* `%s`\n"+
+			"   └─ *DURATION :* `%s`\n",
+		escapeMarkdownV2(fmt.Sprintf(valueFormat, alert.Value)),
+		escapeMarkdownV2(alert.RecoveryTime.Format("2006/01/02 15:04")),
+		escapeMarkdownV2(formatOutageDuration(alert.TimeDown, alert.RecoveryTime)),
+	)
+	messageBuilder.WriteString(info)
+
+	return t.sendMessage(messageBuilder.String())
+}
+
+func dropPercent(baseline, value float64) float64 {
+	if baseline <= 0 {
+		return 0
+	}
+	return (baseline - value) / baseline * 100
+}
+
+func (t *telegramNotifier) SendIpTransitAlert(alert types.IpTransitAlert) error {
+	var messageBuilder strings.Builder
+	friendlyGatewayName := t.DetermineFriendlyGatewayName(alert.GatewayName)
+
+	var title, event string
+	switch alert.Event {
+	case "BOTH_DOWN":
+		title = "🚨 *CRITICAL ALERT \\- SERVICE IMPACTING* 🚨"
+		event = "BOTH IP TRANSITS DOWN"
+	case "RESTORED":
+		title = "🌟 *RECOVERY INFO* 🌟"
+		event = "IP TRANSIT RESTORED TO MAIN"
+	default:
+		title = "⚠️ *IP TRANSIT FAILOVER* ⚠️"
+		event = "MAIN TRANSIT DOWN, RUNNING ON BACKUP"
+	}
+	eventLine := fmt.Sprintf("🗒 EVENT : *%s*", escapeMarkdownV2(event))
+	gatewayLine := fmt.Sprintf("📡 GATEWAY : *%s*", escapeMarkdownV2(friendlyGatewayName))
+	header := fmt.Sprintf("%s\n\n%s\n%s\n%s\n\n", title, eventLine, gatewayLine, escapeMarkdownV2("━━━━━━━ ✦ ━━━━━━━"))
+	messageBuilder.WriteString(header)
+
+	var timeLines string
+	if alert.Event == "RESTORED" {
+		timeLines = fmt.Sprintf(
+			"   ├─ *RECOVERED AT :* `%s`\n"+
+				"   └─ *OFF MAIN FOR :* `%s`\n",
+			escapeMarkdownV2(alert.RecoveryTime.Format("2006/01/02 15:04")),
+			escapeMarkdownV2(formatOutageDuration(alert.StartTime, alert.RecoveryTime)),
+		)
+	} else {
+		timeLines = fmt.Sprintf(
+			"   ├─ *SEVERITY :* `%s`\n"+
+				"   ├─ *START :* `%s`\n"+
+				"   └─ *DURATION :* `%s`\n",
+			escapeMarkdownV2(alert.Severity),
+			escapeMarkdownV2(alert.StartTime.Format("2006/01/02 15:04")),
+			escapeMarkdownV2(formatDuration(alert.StartTime)),
+		)
+	}
+
+	info := fmt.Sprintf(
+		"   ┌─ *MAIN :* `%s`\n"+
+			"   ├─ *BACKUP :* `%s`\n"+
+			"%s",
+		escapeMarkdownV2(alert.MainStatus),
+		escapeMarkdownV2(alert.BackupStatus),
+		timeLines,
+	)
+	messageBuilder.WriteString(info)
+
+	return t.sendMessage(messageBuilder.String())
+}
+
+func (t *telegramNotifier) SendNmsDownAlert(alert types.NmsDownAlert) error {
+	var messageBuilder strings.Builder
+	friendlyGatewayName := t.DetermineFriendlyGatewayName(alert.GatewayName)
+
+	alertTitle := "⚠️ *NMS ALERT* ⚠️"
+	eventLine := "🗒 EVENT : *NMS UNREACHABLE*"
+	gatewayLine := fmt.Sprintf("📡 GATEWAY : *%s*", escapeMarkdownV2(friendlyGatewayName))
+	header := fmt.Sprintf("%s\n\n%s\n%s\n%s\n\n", alertTitle, eventLine, gatewayLine, escapeMarkdownV2("━━━━━━━ ✦ ━━━━━━━"))
+	messageBuilder.WriteString(header)
+
+	info := fmt.Sprintf(
+		"   ┌─ *STATUS :* `%s`\n"+
+			"   ├─ *SEVERITY :* `%s`\n"+
+			"   └─ *START :* `%s`\n",
+		escapeMarkdownV2(alert.Status),
+		escapeMarkdownV2(alert.Severity),
+		escapeMarkdownV2(alert.StartTime.Format("2006/01/02 15:04")),
+	)
+	messageBuilder.WriteString(info)
+
+	return t.sendMessage(messageBuilder.String())
+}
+
+func (t *telegramNotifier) SendNmsUpAlert(alert types.NmsUpAlert) error {
+	var messageBuilder strings.Builder
+	friendlyGatewayName := t.DetermineFriendlyGatewayName(alert.GatewayName)
+
+	title := "🌟 *RECOVERY INFO* 🌟"
+	eventLine := "🗒 EVENT : *NMS REACHABLE*"
+	gatewayLine := fmt.Sprintf("📡 GATEWAY : *%s*", escapeMarkdownV2(friendlyGatewayName))
+	header := fmt.Sprintf("%s\n\n%s\n%s\n%s\n\n", title, eventLine, gatewayLine, escapeMarkdownV2("━━━━━━━ ✦ ━━━━━━━"))
+	messageBuilder.WriteString(header)
+
+	info := fmt.Sprintf(
+		"   ├─ *RECOVERED AT :* `%s`\n"+
+			"   └─ *DURATION :* `%s`\n",
+		escapeMarkdownV2(alert.RecoveryTime.Format("2006/01/02 15:04")),
+		escapeMarkdownV2(formatOutageDuration(alert.TimeDown, alert.RecoveryTime)),
+	)
+	messageBuilder.WriteString(info)
+
+	return t.sendMessage(messageBuilder.String())
+}
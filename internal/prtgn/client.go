@@ -0,0 +1,248 @@
+package prtgn
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// maxErrorBodySize membatasi isi body error yang dibaca dari PRTG.
+const maxErrorBodySize = 4096
+
+// APIError adalah respons non-200 dari PRTG. Message diambil dari field
+// "error" pada body JSON PRTG jika ada.
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("PRTG mengembalikan status %d", e.StatusCode)
+	}
+	return fmt.Sprintf("PRTG mengembalikan status %d: %s", e.StatusCode, e.Message)
+}
+
+// Client adalah klien HTTP PRTG. Semua error yang dikembalikan sudah bebas
+// dari apitoken sehingga aman untuk dicatat di log.
+type Client struct {
+	baseURL    string
+	apiToken   string
+	httpClient *http.Client
+	timeout    time.Duration
+}
+
+func NewClient(baseURL, apiToken string, timeout time.Duration) *Client {
+	return &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		apiToken:   apiToken,
+		httpClient: &http.Client{Timeout: timeout},
+		timeout:    timeout,
+	}
+}
+
+// GetSensorDetails mengambil detail satu sensor dari endpoint getsensordetails.json.
+func (c *Client) GetSensorDetails(ctx context.Context, id string) (*SensorData, error) {
+	var prtgResp PrtgResponse
+	if err := c.get(ctx, "/api/getsensordetails.json", url.Values{"id": {id}}, &prtgResp); err != nil {
+		return nil, err
+	}
+	return &prtgResp.SensorData, nil
+}
+
+// TableSensor adalah satu baris hasil table.json?content=sensors.
+type TableSensor struct {
+	ObjID  int    `json:"objid"`
+	Sensor string `json:"sensor"`
+	Device string `json:"device"`
+	Group  string `json:"group"`
+	Tags   string `json:"tags"`
+}
+
+// ListSensors mengambil daftar sensor dari table.json, difilter berdasarkan tag
+// dan/atau group di sisi PRTG. Parameter kosong berarti tanpa filter tersebut.
+func (c *Client) ListSensors(ctx context.Context, tag, group string) ([]TableSensor, error) {
+	params := url.Values{
+		"content": {"sensors"},
+		"columns": {"objid,sensor,device,group,tags"},
+		"count":   {"*"},
+	}
+	if tag != "" {
+		params.Set("filter_tags", fmt.Sprintf("@tag(%s)", tag))
+	}
+	if group != "" {
+		params.Set("filter_group", group)
+	}
+
+	var table struct {
+		Sensors []TableSensor `json:"sensors"`
+	}
+	if err := c.get(ctx, "/api/table.json", params, &table); err != nil {
+		return nil, err
+	}
+	return table.Sensors, nil
+}
+
+// Channel adalah satu channel sensor dari table.json?content=channels.
+type Channel struct {
+	ObjID     int    `json:"objid"`
+	Name      string `json:"name"`
+	LastValue string `json:"lastvalue"`
+}
+
+// GetChannels mengambil nilai terakhir setiap channel sebuah sensor.
+func (c *Client) GetChannels(ctx context.Context, id string) ([]Channel, error) {
+	params := url.Values{
+		"content": {"channels"},
+		"id":      {id},
+		"columns": {"objid,name,lastvalue"},
+	}
+	var table struct {
+		Channels []Channel `json:"channels"`
+	}
+	if err := c.get(ctx, "/api/table.json", params, &table); err != nil {
+		return nil, err
+	}
+	return table.Channels, nil
+}
+
+// GetHistoricData mengambil data historis sensor dari historicdata.json.
+// start/end mengikuti zona waktu server PRTG; avgSeconds 0 berarti data mentah.
+func (c *Client) GetHistoricData(ctx context.Context, id string, start, end time.Time, avgSeconds int) ([]map[string]interface{}, error) {
+	const layout = "2006-01-02-15-04-05"
+	params := url.Values{
+		"id":    {id},
+		"sdate": {start.Format(layout)},
+		"edate": {end.Format(layout)},
+		"avg":   {fmt.Sprintf("%d", avgSeconds)},
+	}
+
+	var historic struct {
+		HistData []map[string]interface{} `json:"histdata"`
+	}
+	if err := c.get(ctx, "/api/historicdata.json", params, &historic); err != nil {
+		return nil, err
+	}
+	return historic.HistData, nil
+}
+
+// PauseSensor menjeda sensor selama duration lewat pauseobjectfor.htm.
+// PRTG melanjutkan sensor secara otomatis setelah durasi habis.
+func (c *Client) PauseSensor(ctx context.Context, id string, duration time.Duration, message string) error {
+	minutes := int(duration.Minutes())
+	if minutes < 1 {
+		minutes = 1
+	}
+	params := url.Values{
+		"id":       {id},
+		"duration": {fmt.Sprintf("%d", minutes)},
+		"pausemsg": {message},
+	}
+	return c.get(ctx, "/api/pauseobjectfor.htm", params, nil)
+}
+
+// ResumeSensor melanjutkan sensor yang dijeda lewat pause.htm?action=1.
+func (c *Client) ResumeSensor(ctx context.Context, id string) error {
+	return c.get(ctx, "/api/pause.htm", url.Values{"id": {id}, "action": {"1"}}, nil)
+}
+
+// AcknowledgeAlarm meng-acknowledge alarm sensor yang sedang Down.
+func (c *Client) AcknowledgeAlarm(ctx context.Context, id, message string) error {
+	return c.get(ctx, "/api/acknowledgealarm.htm", url.Values{"id": {id}, "ackmsg": {message}}, nil)
+}
+
+func (c *Client) get(ctx context.Context, path string, params url.Values, target interface{}) error {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	params.Set("apitoken", c.apiToken)
+	reqURL := c.baseURL + path + "?" + params.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return c.redactError(fmt.Errorf("gagal membuat request PRTG: %w", err))
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return c.redactError(fmt.Errorf("gagal request ke PRTG: %w", err))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+		return c.redactError(&APIError{StatusCode: resp.StatusCode, Message: parseErrorBody(body)})
+	}
+
+	if target == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(target); err != nil {
+		return c.redactError(fmt.Errorf("gagal parsing JSON dari PRTG: %w", err))
+	}
+	return nil
+}
+
+// parseErrorBody membaca pesan error PRTG ({"prtg-version": ..., "error": ...}),
+// atau memakai body mentah jika bukan JSON.
+func parseErrorBody(body []byte) string {
+	var prtgErr struct {
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(body, &prtgErr); err == nil && prtgErr.Error != "" {
+		return prtgErr.Error
+	}
+	return strings.TrimSpace(string(body))
+}
+
+// redactError mengganti apitoken di pesan error, termasuk URL di dalam *url.Error.
+func (c *Client) redactError(err error) error {
+	if err == nil || c.apiToken == "" {
+		return err
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		apiErr.Message = c.Redact(apiErr.Message)
+		return apiErr
+	}
+	return &redactedError{msg: c.Redact(err.Error()), err: c.redactCause(err)}
+}
+
+// redactCause mengembalikan penyebab error yang aman dibuka lewat Unwrap.
+// *url.Error disalin dengan URL yang sudah disensor; error lain yang pesannya
+// memuat apitoken tidak dibuka sama sekali.
+func (c *Client) redactCause(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return &url.Error{Op: urlErr.Op, URL: c.Redact(urlErr.URL), Err: urlErr.Err}
+	}
+	if c.Redact(err.Error()) != err.Error() {
+		return nil
+	}
+	return err
+}
+
+// redactedError menyimpan penyebab yang sudah disensor agar errors.Is (mis.
+// context.DeadlineExceeded) tetap berfungsi tanpa membocorkan apitoken.
+type redactedError struct {
+	msg string
+	err error
+}
+
+func (e *redactedError) Error() string { return e.msg }
+func (e *redactedError) Unwrap() error { return e.err }
+
+// Redact menghapus apitoken (mentah maupun ter-encode) dari sebuah string.
+func (c *Client) Redact(s string) string {
+	if c.apiToken == "" {
+		return s
+	}
+	s = strings.ReplaceAll(s, c.apiToken, "REDACTED")
+	return strings.ReplaceAll(s, url.QueryEscape(c.apiToken), "REDACTED")
+}
@@ -0,0 +1,42 @@
+package prtgn
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClientErrorsDoNotLeakToken(t *testing.T) {
+	const token = "token-rahasia"
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer slow.Close()
+
+	client := NewClient(slow.URL, token, 50*time.Millisecond)
+	_, err := client.GetSensorDetails(context.Background(), "2101")
+	if err == nil {
+		t.Fatal("GetSensorDetails berhasil, want timeout")
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("errors.Is(err, context.DeadlineExceeded) = false untuk %v", err)
+	}
+
+	for unwrapped := err; unwrapped != nil; unwrapped = errors.Unwrap(unwrapped) {
+		if strings.Contains(unwrapped.Error(), token) {
+			t.Fatalf("error dalam rantai memuat apitoken: %v", unwrapped)
+		}
+	}
+	var urlErr *url.Error
+	if !errors.As(err, &urlErr) {
+		t.Fatalf("errors.As(*url.Error) = false untuk %v", err)
+	}
+	if strings.Contains(urlErr.URL, token) {
+		t.Errorf("url.Error.URL memuat apitoken: %s", urlErr.URL)
+	}
+}
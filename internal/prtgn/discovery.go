@@ -0,0 +1,148 @@
+package prtgn
+
+import (
+	configs "bella/config"
+	"bella/internal/types"
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// discoveryTimeout membatasi satu putaran discovery untuk semua aturan.
+const discoveryTimeout = 2 * time.Minute
+
+// RunDiscovery mencari sensor di PRTG sesuai aturan discovery dan memperbarui
+// daftar sensor yang dipantau. Perubahan diumumkan ke chat kecuali pada discovery
+// pertama setelah start. Alert aktif milik sensor yang hilang ditutup, karena
+// sensor tersebut tidak lagi dicek, dan ditandai di notifikasi.
+func (p *PRTGAPI) RunDiscovery() {
+	if len(p.DiscoveryRules) == 0 {
+		return
+	}
+	slog.Info("Memulai discovery sensor PRTG...", "rules", len(p.DiscoveryRules))
+
+	ctx, cancel := context.WithTimeout(context.Background(), discoveryTimeout)
+	defer cancel()
+
+	found := make(map[string]configs.PRTGSensor)
+	for i, rule := range p.DiscoveryRules {
+		sensors, err := p.discoverRule(ctx, rule)
+		if err != nil {
+			// Hasil parsial tidak dipakai agar sensor tidak dianggap hilang karena error sementara.
+			slog.Error("Gagal menjalankan aturan discovery PRTG, daftar sensor tidak diubah", "rule", i+1, "error", err)
+			return
+		}
+		for _, sensor := range sensors {
+			if _, exists := found[sensor.ID]; !exists {
+				found[sensor.ID] = sensor
+			}
+		}
+	}
+
+	p.mu.Lock()
+	previous := p.discovered
+	firstRun := previous == nil
+	p.discovered = found
+	p.mu.Unlock()
+
+	var added, removed []types.PRTGSensorChange
+	for id, sensor := range found {
+		if _, exists := previous[id]; !exists {
+			added = append(added, toSensorChange(sensor))
+		}
+	}
+	for id, sensor := range previous {
+		if _, exists := found[id]; !exists {
+			change := toSensorChange(sensor)
+			if _, active := p.State.GetAlertByKey(getAlertKey(sensor)); active {
+				slog.Warn("Sensor PRTG hilang dari discovery, alert aktif ditutup tanpa notifikasi UP", "key", getAlertKey(sensor), "sensor", sensor.Name)
+				p.State.RemoveAlertByKey(getAlertKey(sensor))
+				change.AlertClosed = true
+			}
+			removed = append(removed, change)
+		}
+	}
+	sortSensorChanges(added)
+	sortSensorChanges(removed)
+
+	slog.Info("Discovery sensor PRTG selesai", "total", len(found), "added", len(added), "removed", len(removed))
+	if firstRun || (len(added) == 0 && len(removed) == 0) {
+		return
+	}
+	if err := p.Notifier.SendPrtgDiscoveryAlert(added, removed); err != nil {
+		slog.Error("Gagal mengirim notifikasi perubahan sensor PRTG", "error", err)
+	}
+}
+
+func (p *PRTGAPI) discoverRule(ctx context.Context, rule configs.PRTGDiscoveryRule) ([]configs.PRTGSensor, error) {
+	var devicePattern, sensorPattern *regexp.Regexp
+	if rule.DevicePattern != "" {
+		devicePattern = regexp.MustCompile(rule.DevicePattern)
+	}
+	if rule.SensorPattern != "" {
+		sensorPattern = regexp.MustCompile(rule.SensorPattern)
+	}
+
+	rows, err := p.Client.ListSensors(ctx, rule.Tag, rule.Group)
+	if err != nil {
+		return nil, err
+	}
+
+	var sensors []configs.PRTGSensor
+	for _, row := range rows {
+		if devicePattern != nil && !devicePattern.MatchString(row.Device) {
+			continue
+		}
+		if sensorPattern != nil && !sensorPattern.MatchString(row.Sensor) {
+			continue
+		}
+		gateway := rule.Gateway
+		if gateway == "" {
+			gateway = guessGateway(row.Device)
+		}
+		if gateway == "" {
+			slog.Warn("Gateway sensor PRTG hasil discovery tidak dapat ditentukan, sensor dilewati", "id", row.ObjID, "device", row.Device, "sensor", row.Sensor)
+			continue
+		}
+		name := fmt.Sprintf("%s / %s", row.Device, row.Sensor)
+		sensors = append(sensors, rule.Sensor(strconv.Itoa(row.ObjID), name, gateway))
+	}
+	return sensors, nil
+}
+
+// guessGateway menebak gateway dari kode atau nama lokasi di nama device.
+func guessGateway(device string) string {
+	upper := strings.ToUpper(device)
+	switch {
+	case strings.Contains(upper, "JYP") || strings.Contains(upper, "JAYAPURA"):
+		return "JAYAPURA"
+	case strings.Contains(upper, "MNK") || strings.Contains(upper, "MANOKWARI"):
+		return "MANOKWARI"
+	case strings.Contains(upper, "TMK") || strings.Contains(upper, "TIMIKA"):
+		return "TIMIKA"
+	}
+	return ""
+}
+
+func toSensorChange(sensor configs.PRTGSensor) types.PRTGSensorChange {
+	return types.PRTGSensorChange{
+		ID:       sensor.ID,
+		Name:     sensor.Name,
+		Gateway:  sensor.Gateway,
+		Category: sensor.Category,
+	}
+}
+
+func sortSensorChanges(changes []types.PRTGSensorChange) {
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Gateway != changes[j].Gateway {
+			return changes[i].Gateway < changes[j].Gateway
+		}
+		return changes[i].Name < changes[j].Name
+	})
+}
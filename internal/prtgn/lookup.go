@@ -0,0 +1,130 @@
+package prtgn
+
+import (
+	configs "bella/config"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SensorStatus adalah kondisi terkini satu sensor untuk ditampilkan di bot.
+type SensorStatus struct {
+	Sensor      configs.PRTGSensor
+	Name        string
+	Device      string
+	Status      string
+	LastValue   string
+	LastMessage string
+	LastCheck   time.Time
+	LastUp      time.Time
+	LastDown    time.Time
+}
+
+// HistoricPoint adalah satu titik data historis. Value dalam Kbit/s untuk
+// sensor traffic, atau angka mentah untuk sensor lain.
+type HistoricPoint struct {
+	Time  time.Time
+	Value float64
+}
+
+// FindSensors mencari sensor yang dipantau di gateway berdasarkan kategori
+// (mis. NIF, IPTX), id, atau potongan nama sensor.
+func (p *PRTGAPI) FindSensors(gateway, query string) []configs.PRTGSensor {
+	var matches []configs.PRTGSensor
+	for _, sensor := range p.activeSensors() {
+		if sensor.Gateway != gateway {
+			continue
+		}
+		if strings.EqualFold(sensor.Category, query) ||
+			sensor.ID == query ||
+			strings.Contains(strings.ToLower(sensor.Name), strings.ToLower(query)) {
+			matches = append(matches, sensor)
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].Name < matches[j].Name })
+	return matches
+}
+
+func (p *PRTGAPI) GetSensorStatus(ctx context.Context, sensor configs.PRTGSensor) (*SensorStatus, error) {
+	details, err := p.Client.GetSensorDetails(ctx, sensor.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &SensorStatus{
+		Sensor:      sensor,
+		Name:        details.Name,
+		Device:      details.ParentDeviceName,
+		Status:      details.StatusText,
+		LastValue:   details.LastValue,
+		LastMessage: details.LastMessage,
+		LastCheck:   p.parseOADate(details.LastCheck),
+		LastUp:      p.parseOADate(details.LastUp),
+		LastDown:    p.parseOADate(details.LastDown),
+	}, nil
+}
+
+// GetSensorHistory mengambil data historis sensor selama queryRange terakhir.
+// Rata-rata PRTG dipilih agar hasilnya sekitar 100 titik.
+func (p *PRTGAPI) GetSensorHistory(ctx context.Context, sensor configs.PRTGSensor, queryRange time.Duration) ([]HistoricPoint, error) {
+	end := time.Now().In(p.Timezone)
+	start := end.Add(-queryRange)
+	avgSeconds := int(queryRange.Seconds() / 100)
+	if avgSeconds < 60 {
+		avgSeconds = 60
+	}
+
+	rows, err := p.Client.GetHistoricData(ctx, sensor.ID, start, end, avgSeconds)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	channel := pickHistoricChannel(rows[0])
+	if channel == "" {
+		return nil, fmt.Errorf("data historis sensor %s tidak memiliki channel nilai", sensor.ID)
+	}
+
+	points := make([]HistoricPoint, 0, len(rows))
+	for _, row := range rows {
+		display, ok := row[channel].(string)
+		if !ok || display == "" {
+			continue
+		}
+		value, _, err := ParseValue(display)
+		if err != nil {
+			continue
+		}
+		var at time.Time
+		if raw, ok := row["datetime_raw"].(float64); ok {
+			at = OADateToTime(raw).In(p.Timezone)
+		}
+		points = append(points, HistoricPoint{Time: at, Value: value})
+	}
+	return points, nil
+}
+
+// pickHistoricChannel memilih kolom nilai dari baris histdata. Channel
+// "Traffic Total" diutamakan, selain itu channel pertama menurut abjad.
+func pickHistoricChannel(row map[string]interface{}) string {
+	var channels []string
+	for key := range row {
+		if key == "datetime" || key == "coverage" || strings.HasSuffix(key, "_raw") {
+			continue
+		}
+		channels = append(channels, key)
+	}
+	sort.Strings(channels)
+	for _, channel := range channels {
+		if strings.HasPrefix(channel, "Traffic Total") {
+			return channel
+		}
+	}
+	if len(channels) == 0 {
+		return ""
+	}
+	return channels[0]
+}
@@ -0,0 +1,72 @@
+package prtgn
+
+import (
+	configs "bella/config"
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// pauseGracePeriod adalah waktu tunggu sampai status "Paused" terlihat di
+// getsensordetails setelah jeda dikirim dari Bella.
+const pauseGracePeriod = 5 * time.Minute
+
+// PauseSensor menjeda sensor di PRTG. Selama sensor berstatus Paused, Bella
+// tidak mengirim alert untuk sensor tersebut.
+func (p *PRTGAPI) PauseSensor(ctx context.Context, sensor configs.PRTGSensor, duration time.Duration, message string) error {
+	if err := p.Client.PauseSensor(ctx, sensor.ID, duration, message); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	if p.pausedUntil == nil {
+		p.pausedUntil = make(map[string]time.Time)
+	}
+	p.pausedUntil[sensor.ID] = time.Now().Add(min(duration, pauseGracePeriod))
+	p.mu.Unlock()
+
+	slog.Info("Sensor PRTG dijeda", "sensor", sensor.Name, "id", sensor.ID, "duration", duration, "message", message)
+	return nil
+}
+
+func (p *PRTGAPI) ResumeSensor(ctx context.Context, sensor configs.PRTGSensor) error {
+	if err := p.Client.ResumeSensor(ctx, sensor.ID); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	delete(p.pausedUntil, sensor.ID)
+	p.mu.Unlock()
+
+	slog.Info("Sensor PRTG dilanjutkan", "sensor", sensor.Name, "id", sensor.ID)
+	return nil
+}
+
+func (p *PRTGAPI) AcknowledgeAlarm(ctx context.Context, sensor configs.PRTGSensor, message string) error {
+	if err := p.Client.AcknowledgeAlarm(ctx, sensor.ID, message); err != nil {
+		return err
+	}
+	slog.Info("Alarm sensor PRTG di-acknowledge", "sensor", sensor.Name, "id", sensor.ID, "message", message)
+	return nil
+}
+
+// isSilenced menandakan sensor sedang dijeda menurut status PRTG (mis. "Paused
+// by User"), atau jeda baru saja dikirim dari Bella dan belum tercermin di PRTG.
+func (p *PRTGAPI) isSilenced(id, statusText string) bool {
+	if strings.HasPrefix(strings.ToLower(statusText), "paused") {
+		return true
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	until, ok := p.pausedUntil[id]
+	if !ok {
+		return false
+	}
+	if time.Now().After(until) {
+		delete(p.pausedUntil, id)
+		return false
+	}
+	return true
+}
@@ -1,17 +1,18 @@
 package prtgn
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 	"log/slog"
 	"math"
-	"net/http"
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	configs "bella/config"
+	"bella/internal/freshness"
 	"bella/internal/notifier"
 	"bella/internal/state"
 	"bella/internal/types"
@@ -32,15 +33,30 @@ type SensorData struct {
 }
 type PRTGAPIInterface interface {
 	RunPeriodicChecks()
+	RunDiscovery()
+	FindSensors(gateway, query string) []configs.PRTGSensor
+	GetSensorStatus(ctx context.Context, sensor configs.PRTGSensor) (*SensorStatus, error)
+	GetSensorHistory(ctx context.Context, sensor configs.PRTGSensor, queryRange time.Duration) ([]HistoricPoint, error)
+	PauseSensor(ctx context.Context, sensor configs.PRTGSensor, duration time.Duration, message string) error
+	ResumeSensor(ctx context.Context, sensor configs.PRTGSensor) error
+	AcknowledgeAlarm(ctx context.Context, sensor configs.PRTGSensor, message string) error
 }
 type PRTGAPI struct {
-	BaseURL     string
-	APIToken    string
+	Client      *Client
+	Concurrency int
 	Notifier    notifier.Notifier
 	State       *state.Manager
-	NifSensors  map[string]string
-	IptxSensors map[string]string
+	Sensors     []configs.PRTGSensor
 	Timezone    *time.Location
+
+	DiscoveryRules []configs.PRTGDiscoveryRule
+	mu             sync.RWMutex
+	discovered     map[string]configs.PRTGSensor
+	pausedUntil    map[string]time.Time
+
+	Freshness *freshness.Checker
+	// StaleMaxAge mengembalikan umur maksimum lastcheck untuk kategori sensor di gateway.
+	StaleMaxAge func(category, gateway string) time.Duration
 }
 
 func NewPRTGAPI(config *configs.AppConfig, notifier notifier.Notifier, stateMgr *state.Manager) PRTGAPIInterface {
@@ -51,76 +67,107 @@ func NewPRTGAPI(config *configs.AppConfig, notifier notifier.Notifier, stateMgr
 
 	wibLocation := time.FixedZone("WIB", 7*60*60)
 
-	return &PRTGAPI{
-		BaseURL:  config.PRTGUrl,
-		APIToken: config.PRTGAPITOKEN,
-		Notifier: notifier,
-		State:    stateMgr,
-		NifSensors: map[string]string{
-			"JAYAPURA": config.NIF_JYP, "MANOKWARI": config.NIF_MNK, "TIMIKA": config.NIF_TMK,
-		},
-		IptxSensors: map[string]string{
-			"JAYAPURA": config.IPTX_JYP, "MANOKWARI": config.IPTX_MNK, "TIMIKA": config.IPTX_TMK,
+	if len(config.PRTGSensors) == 0 && len(config.PRTGDiscoveryRules) == 0 {
+		slog.Warn("Katalog sensor PRTG kosong. Tidak ada sensor PRTG yang akan dipantau.")
+	}
+
+	api := &PRTGAPI{
+		Client:         NewClient(config.PRTGUrl, config.PRTGAPITOKEN, config.PRTGTimeout),
+		Concurrency:    config.PRTGConcurrency,
+		Notifier:       notifier,
+		State:          stateMgr,
+		Sensors:        config.PRTGSensors,
+		DiscoveryRules: config.PRTGDiscoveryRules,
+		Timezone:       wibLocation,
+		Freshness:      freshness.NewChecker(notifier, stateMgr),
+		StaleMaxAge: func(category, gateway string) time.Duration {
+			return config.StaleMaxAge("PRTG", "prtg_"+category, gateway)
 		},
-		Timezone: wibLocation,
 	}
+	api.migrateLegacyAlertKeys()
+	return api
 }
 
 func (p *PRTGAPI) RunPeriodicChecks() {
-	slog.Info("Memulai pengecekan periodik PRTG untuk semua sensor...")
+	sensors := p.activeSensors()
+	slog.Info("Memulai pengecekan periodik PRTG untuk semua sensor...", "sensors", len(sensors))
+	p.pruneStaleAlerts(sensors)
 
 	previousAlerts := p.State.GetActiveAlerts()
 
-	allSensors := map[string]map[string]string{
-		"NIF":  p.NifSensors,
-		"IPTX": p.IptxSensors,
+	concurrency := p.Concurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	semaphore := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for _, sensor := range sensors {
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(sensor configs.PRTGSensor) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+			p.checkSensorAndNotify(sensor, previousAlerts)
+		}(sensor)
 	}
+	wg.Wait()
+}
+
+// activeSensors menggabungkan katalog statis dengan sensor hasil discovery.
+// Entri katalog statis diutamakan jika id yang sama ditemukan lewat discovery.
+func (p *PRTGAPI) activeSensors() []configs.PRTGSensor {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
 
-	for sensorType, sensorsMap := range allSensors {
-		for location, id := range sensorsMap {
-			p.checkSensorAndNotify(location, id, sensorType, previousAlerts)
+	sensors := make([]configs.PRTGSensor, 0, len(p.Sensors)+len(p.discovered))
+	static := make(map[string]bool, len(p.Sensors))
+	for _, sensor := range p.Sensors {
+		static[sensor.ID] = true
+		sensors = append(sensors, sensor)
+	}
+	for id, sensor := range p.discovered {
+		if !static[id] {
+			sensors = append(sensors, sensor)
 		}
 	}
+	return sensors
 }
 
-func (p *PRTGAPI) checkSensorAndNotify(location, id, sensorType string, previousAlerts map[string]state.ActiveAlert) {
-	const thresholdKbps = 1000.0
-	alertKey := fmt.Sprintf("prtg_%s_%s", sensorType, location)
+func (p *PRTGAPI) checkSensorAndNotify(sensor configs.PRTGSensor, previousAlerts map[string]state.ActiveAlert) {
+	location := sensor.Gateway
+	sensorType := sensor.Category
+	alertKey := getAlertKey(sensor)
 
-	url := fmt.Sprintf("%s/api/getsensordetails.json?id=%s&apitoken=%s", p.BaseURL, id, p.APIToken)
-	resp, err := http.Get(url)
+	details, err := p.Client.GetSensorDetails(context.Background(), sensor.ID)
 	if err != nil {
-		slog.Error("Gagal request ke PRTG", "sensor", location, "error", err)
+		slog.Error("Gagal mengambil data sensor dari PRTG", "sensor", sensor.Name, "id", sensor.ID, "error", err)
 		return
 	}
-	defer resp.Body.Close()
 
-	var prtgResp PrtgResponse
-	if err := json.NewDecoder(resp.Body).Decode(&prtgResp); err != nil {
-		slog.Error("Gagal parsing JSON dari PRTG", "sensor", location, "error", err)
+	sensorData := *details
+	if p.isSilenced(sensor.ID, sensorData.StatusText) {
+		slog.Info("Sensor PRTG sedang dijeda, pengecekan dilewati", "sensor", sensor.Name, "id", sensor.ID, "status", sensorData.StatusText)
 		return
 	}
 
-	sensorData := prtgResp.SensorData
-	isCurrentlyDown := false
-	alertValue := sensorData.LastValue
+	lastCheckTime := p.parseOADate(sensorData.LastCheck)
+	if !p.Freshness.Evaluate(freshnessSource(sensor), location, lastCheckTime, p.StaleMaxAge(sensorType, location)) {
+		return
+	}
 
-	if strings.EqualFold(sensorData.StatusText, "Down") {
-		isCurrentlyDown = true
-	} else {
-		valueKbps, err := p.parseAndConvertValue(sensorData.LastValue)
-		if err == nil && valueKbps < thresholdKbps {
-			isCurrentlyDown = true
-			alertValue = fmt.Sprintf("%.2f Kbit/s", valueKbps)
-		}
+	isCurrentlyDown, reason, alertValue, err := p.evaluateRule(context.Background(), sensor, sensorData)
+	if err != nil {
+		// Kondisi sensor tidak bisa dipastikan; alert aktif dibiarkan apa adanya.
+		slog.Warn("Kondisi sensor PRTG tidak dapat dievaluasi, pengecekan dilewati", "sensor", sensor.Name, "id", sensor.ID, "error", err)
+		return
 	}
 
 	_, wasPreviouslyDown := previousAlerts[alertKey]
 
 	if isCurrentlyDown {
 		slog.Warn("Sensor PRTG terdeteksi DOWN, mengirim notifikasi...", "key", alertKey)
-		alertData := p.createDownAlert(location, sensorType, sensorData, alertValue)
-		p.sendDownAlert(alertData)
+		alertData := p.createDownAlert(sensor, sensorData, alertValue, reason)
+		p.sendDownAlert(sensor.Template, alertData)
 
 		if !wasPreviouslyDown {
 			slog.Info("Menambahkan alert PRTG baru ke state", "key", alertKey)
@@ -133,17 +180,12 @@ func (p *PRTGAPI) checkSensorAndNotify(location, id, sensorType string, previous
 
 		lastDownTime := time.Time{}
 		if previousAlert, ok := p.State.GetAlertByKey(alertKey); ok {
-			if detailsMap, ok := previousAlert.Details.(map[string]interface{}); ok {
-				if lastDownStr, ok := detailsMap["last_down"].(string); ok {
-					const layout = "2006-01-02 15:04:05 MST"
-					parsed, err := time.Parse(layout, lastDownStr)
-					if err == nil {
-						lastDownTime = parsed
-					} else {
-						slog.Warn("Gagal parse LastDown dari state", "last_down", lastDownStr, "error", err)
-					}
-				}
-			}
+			lastDownTime = p.getDownSince(previousAlert)
+		}
+
+		recoveryTime := p.parseOADate(sensorData.LastUp)
+		if recoveryTime.IsZero() || recoveryTime.Before(lastDownTime) {
+			recoveryTime = lastCheckTime
 		}
 
 		upAlert := types.PRTGUpAlert{
@@ -151,7 +193,7 @@ func (p *PRTGAPI) checkSensorAndNotify(location, id, sensorType string, previous
 			SensorFullName: sensorData.Name,
 			DeviceName:     sensorData.ParentDeviceName,
 			SensorType:     sensorType,
-			RecoveryTime:   time.Now().In(p.Timezone),
+			RecoveryTime:   recoveryTime,
 			LastDown:       lastDownTime,
 		}
 		if err := p.Notifier.SendPrtgUpAlert(upAlert); err != nil {
@@ -161,60 +203,203 @@ func (p *PRTGAPI) checkSensorAndNotify(location, id, sensorType string, previous
 	}
 }
 
-func (p *PRTGAPI) createDownAlert(location, sensorType string, sensorData SensorData, value string) types.PRTGDownAlert {
+// evaluateRule menentukan apakah sensor bermasalah berdasarkan status PRTG dan
+// rule threshold di katalog. Mengembalikan alasan ("DOWN", "LOW", "HIGH") dan nilai
+// yang ditampilkan di notifikasi. Sensor dengan rule per channel dievaluasi
+// lewat evaluateChannels. Error berarti kondisi sensor tidak diketahui, bukan
+// pulih, sehingga pemanggil tidak boleh mengubah state alert.
+func (p *PRTGAPI) evaluateRule(ctx context.Context, sensor configs.PRTGSensor, sensorData SensorData) (bool, string, string, error) {
+	if strings.EqualFold(sensorData.StatusText, "Down") {
+		return true, "DOWN", sensorData.LastValue, nil
+	}
+	if len(sensor.Channels) > 0 {
+		return p.evaluateChannels(ctx, sensor)
+	}
+	if sensor.Rule == configs.PRTGRuleStatus {
+		return false, "", sensorData.LastValue, nil
+	}
+
+	value, kind, err := ParseValue(sensorData.LastValue)
+	if err != nil {
+		return false, "", sensorData.LastValue, fmt.Errorf("nilai sensor tidak dapat dibaca: %w", err)
+	}
+	if reason := checkThreshold(sensor.Rule, sensor.Threshold, value); reason != "" {
+		return true, reason, FormatValue(value, kind), nil
+	}
+	return false, "", sensorData.LastValue, nil
+}
+
+// evaluateChannels membaca semua channel sensor dan membandingkan setiap channel
+// yang memiliki rule dengan threshold-nya. Semua channel yang melanggar dirangkum
+// dalam satu nilai notifikasi. Jika tidak ada pelanggaran tetapi ada channel
+// yang hilang atau tidak terbaca, hasilnya tidak diketahui (error).
+func (p *PRTGAPI) evaluateChannels(ctx context.Context, sensor configs.PRTGSensor) (bool, string, string, error) {
+	channels, err := p.Client.GetChannels(ctx, sensor.ID)
+	if err != nil {
+		return false, "", "", fmt.Errorf("gagal mengambil channel sensor: %w", err)
+	}
+
+	var reason string
+	var violations []string
+	var unknown []string
+	for _, channelRule := range sensor.Channels {
+		channel, ok := findChannel(channels, channelRule.Channel)
+		if !ok {
+			slog.Warn("Channel sensor PRTG tidak ditemukan", "sensor", sensor.Name, "id", sensor.ID, "channel", channelRule.Channel)
+			unknown = append(unknown, channelRule.Channel)
+			continue
+		}
+		value, kind, err := ParseValue(channel.LastValue)
+		if err != nil {
+			unknown = append(unknown, channelRule.Channel)
+			continue
+		}
+		if channelReason := checkThreshold(channelRule.Rule, channelRule.Threshold, value); channelReason != "" {
+			if reason == "" {
+				reason = channelReason
+			}
+			violations = append(violations, fmt.Sprintf("%s: %s (%s)", channel.Name, FormatValue(value, kind), channelReason))
+		}
+	}
+	if len(violations) > 0 {
+		return true, reason, strings.Join(violations, "; "), nil
+	}
+	if len(unknown) > 0 {
+		return false, "", "", fmt.Errorf("channel tidak ditemukan atau tidak terbaca: %s", strings.Join(unknown, ", "))
+	}
+	return false, "", "", nil
+}
+
+// checkThreshold mengembalikan "LOW" atau "HIGH" jika nilai melanggar rule.
+func checkThreshold(rule string, threshold, value float64) string {
+	switch {
+	case rule == configs.PRTGRuleMin && value < threshold:
+		return "LOW"
+	case rule == configs.PRTGRuleMax && value > threshold:
+		return "HIGH"
+	}
+	return ""
+}
+
+func findChannel(channels []Channel, name string) (Channel, bool) {
+	for _, channel := range channels {
+		if strings.EqualFold(channel.Name, name) || strconv.Itoa(channel.ObjID) == name {
+			return channel, true
+		}
+	}
+	return Channel{}, false
+}
+
+// migrateLegacyAlertKeys memindahkan alert aktif berformat lama (prtg_<TIPE>_<GATEWAY>)
+// ke key per sensor agar pemulihannya tetap terdeteksi.
+func (p *PRTGAPI) migrateLegacyAlertKeys() {
+	for _, sensor := range p.Sensors {
+		legacyKey := fmt.Sprintf("prtg_%s_%s", sensor.Category, sensor.Gateway)
+		alert, ok := p.State.GetAlertByKey(legacyKey)
+		if !ok {
+			continue
+		}
+		if _, exists := p.State.GetAlertByKey(getAlertKey(sensor)); !exists {
+			p.State.AddAlert(getAlertKey(sensor), alert)
+		}
+		p.State.RemoveAlertByKey(legacyKey)
+		slog.Info("Alert PRTG lama dipindahkan ke key per sensor", "from", legacyKey, "to", getAlertKey(sensor))
+	}
+}
+
+// freshnessSource adalah nama sumber data sensor untuk freshness.Checker.
+func freshnessSource(sensor configs.PRTGSensor) string {
+	return fmt.Sprintf("prtg_%s_%s", sensor.Category, sensor.ID)
+}
+
+// pruneStaleAlerts menghapus alert stale milik sensor yang sudah tidak ada di
+// katalog maupun hasil discovery. Dilewati selama discovery belum pernah berhasil
+// agar alert sensor hasil discovery tidak ikut terhapus.
+func (p *PRTGAPI) pruneStaleAlerts(sensors []configs.PRTGSensor) {
+	p.mu.RLock()
+	discoveryPending := len(p.DiscoveryRules) > 0 && p.discovered == nil
+	p.mu.RUnlock()
+	if discoveryPending {
+		return
+	}
+
+	keep := make(map[string]bool, len(sensors))
+	for _, sensor := range sensors {
+		keep[freshness.StaleKey(freshnessSource(sensor), sensor.Gateway)] = true
+	}
+	p.Freshness.Prune("prtg_", keep)
+}
+
+func getAlertKey(sensor configs.PRTGSensor) string {
+	return fmt.Sprintf("prtg_%s_%s_%s", sensor.Category, sensor.Gateway, sensor.ID)
+}
+
+func (p *PRTGAPI) createDownAlert(sensor configs.PRTGSensor, sensorData SensorData, value, reason string) types.PRTGDownAlert {
 	lastDown := p.convertOAtoTime(sensorData.LastDown)
+	downSince := p.parseOADate(sensorData.LastDown)
+	if downSince.IsZero() {
+		// Sensor traffic rendah tidak selalu berstatus Down di PRTG, sehingga lastdown
+		// kosong. Waktu pengecekan terakhir PRTG dipakai sebagai awal gangguan.
+		downSince = p.parseOADate(sensorData.LastCheck)
+	}
 
-	if sensorType == "IPTX" && (lastDown == "-" || lastDown == "") {
-		lastDown = time.Now().In(p.Timezone).Format("2006-01-02 15:04:05 WIB")
+	if reason != "DOWN" && (lastDown == "-" || lastDown == "") && !downSince.IsZero() {
+		lastDown = downSince.Format("2006-01-02 15:04:05 WIB")
 	}
 
 	return types.PRTGDownAlert{
-		Location:       p.Notifier.DetermineFriendlyGatewayName(location),
+		Location:       p.Notifier.DetermineFriendlyGatewayName(sensor.Gateway),
 		SensorFullName: sensorData.Name,
 		DeviceName:     sensorData.ParentDeviceName,
-		SensorType:     sensorType,
+		SensorType:     sensor.Category,
 		Status:         sensorData.StatusText,
+		Reason:         reason,
 		Value:          value,
 		LastMessage:    sensorData.LastMessage,
 		LastCheck:      p.convertOAtoTime(sensorData.LastCheck),
 		LastUp:         p.convertOAtoTime(sensorData.LastUp),
 		LastDown:       lastDown,
+		DownSince:      downSince,
+	}
+}
+
+// getDownSince membaca awal gangguan dari state. Entri lama yang belum memiliki
+// down_since dibaca dari string last_down.
+func (p *PRTGAPI) getDownSince(alert state.ActiveAlert) time.Time {
+	switch details := alert.Details.(type) {
+	case types.PRTGDownAlert:
+		return details.DownSince
+	case map[string]interface{}:
+		if downSinceStr, ok := details["down_since"].(string); ok {
+			if parsed, err := time.Parse(time.RFC3339Nano, downSinceStr); err == nil && !parsed.IsZero() {
+				return parsed
+			}
+		}
+		if lastDownStr, ok := details["last_down"].(string); ok {
+			const layout = "2006-01-02 15:04:05 MST"
+			parsed, err := time.ParseInLocation(layout, lastDownStr, p.Timezone)
+			if err == nil {
+				return parsed
+			}
+			slog.Warn("Gagal parse LastDown dari state", "last_down", lastDownStr, "error", err)
+		}
 	}
+	return time.Time{}
 }
-func (p *PRTGAPI) sendDownAlert(alertData types.PRTGDownAlert) {
+func (p *PRTGAPI) sendDownAlert(template string, alertData types.PRTGDownAlert) {
 	var err error
-	switch alertData.SensorType {
-	case "NIF":
+	switch template {
+	case configs.PRTGTemplateNIF:
 		err = p.Notifier.SendPrtgNIFDownAlert(alertData)
-	case "IPTX":
+	case configs.PRTGTemplateTraffic:
 		err = p.Notifier.SendPrtgTrafficDownAlert(alertData)
+	default:
+		err = p.Notifier.SendPrtgSensorDownAlert(alertData)
 	}
 	if err != nil {
 		slog.Error("Gagal mengirim notifikasi PRTG", "sensor_type", alertData.SensorType, "error", err)
 	}
 }
-func (p *PRTGAPI) parseAndConvertValue(valueStr string) (float64, error) {
-	re := regexp.MustCompile(`[0-9]+(?:\.[0-9]+)?`)
-	numberPart := re.FindString(valueStr)
-	if numberPart == "" {
-		return 0, fmt.Errorf("tidak ada bagian numerik yang ditemukan di '%s'", valueStr)
-	}
-	value, err := strconv.ParseFloat(numberPart, 64)
-	if err != nil {
-		return 0, fmt.Errorf("gagal konversi nilai numerik '%s': %w", numberPart, err)
-	}
-	unitStrLower := strings.ToLower(valueStr)
-	switch {
-	case strings.Contains(unitStrLower, "mbit/s"):
-		return value * 1000, nil
-	case strings.Contains(unitStrLower, "kbit/s"):
-		return value, nil
-	case strings.Contains(unitStrLower, "bit/s"):
-		return value / 1000, nil
-	default:
-		return value, nil
-	}
-}
 
 func (p *PRTGAPI) convertOAtoTime(oaDateStr string) string {
 	re := regexp.MustCompile(`^[0-9]+\.?[0-9]*`)
@@ -223,18 +408,29 @@ func (p *PRTGAPI) convertOAtoTime(oaDateStr string) string {
 		return "-"
 	}
 
-	oaDate, err := strconv.ParseFloat(numberPart, 64)
-	if err != nil {
+	wibTime := p.parseOADate(oaDateStr)
+	if wibTime.IsZero() {
 		return oaDateStr
 	}
 
-	baseDate := time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
-	durationInDays := time.Duration(oaDate * float64(24*time.Hour))
-	utcTime := baseDate.Add(durationInDays)
+	return wibTime.Format("2006-01-02 15:04:05 WIB")
+}
 
-	wibTime := utcTime.In(p.Timezone)
+// parseOADate mengubah OLE Automation date dari PRTG menjadi waktu WIB.
+// Mengembalikan time.Time kosong jika string tidak dapat diparse.
+func (p *PRTGAPI) parseOADate(oaDateStr string) time.Time {
+	re := regexp.MustCompile(`^[0-9]+\.?[0-9]*`)
+	numberPart := re.FindString(oaDateStr)
+	if numberPart == "" {
+		return time.Time{}
+	}
 
-	return wibTime.Format("2006-01-02 15:04:05 WIB")
+	oaDate, err := strconv.ParseFloat(numberPart, 64)
+	if err != nil {
+		return time.Time{}
+	}
+
+	return OADateToTime(oaDate).In(p.Timezone)
 }
 
 func OADateToTime(oaDate float64) time.Time {
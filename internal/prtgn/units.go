@@ -0,0 +1,88 @@
+package prtgn
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Jenis nilai hasil normalisasi. Bitrate dinormalisasi ke Kbit/s, volume ke
+// byte, dan persen tetap dalam persen.
+const (
+	KindBitrate = "bitrate"
+	KindBytes   = "bytes"
+	KindPercent = "percent"
+	KindNumber  = "number"
+)
+
+var valuePattern = regexp.MustCompile(`^\s*(-?[0-9][0-9,]*(?:\.[0-9]+)?|-?\.[0-9]+)\s*(.*)$`)
+
+// bitrateUnits memetakan satuan PRTG ke faktor pengali menuju Kbit/s.
+var bitrateUnits = map[string]float64{
+	"bit/s":   0.001,
+	"kbit/s":  1,
+	"mbit/s":  1000,
+	"gbit/s":  1000 * 1000,
+	"tbit/s":  1000 * 1000 * 1000,
+	"bps":     0.001,
+	"kbps":    1,
+	"mbps":    1000,
+	"gbps":    1000 * 1000,
+	"byte/s":  0.008,
+	"kbyte/s": 8.192,
+	"mbyte/s": 8.192 * 1024,
+	"gbyte/s": 8.192 * 1024 * 1024,
+}
+
+// byteUnits memetakan satuan volume PRTG (basis 1024) ke byte.
+var byteUnits = map[string]float64{
+	"byte":  1,
+	"kbyte": 1024,
+	"mbyte": 1024 * 1024,
+	"gbyte": 1024 * 1024 * 1024,
+	"tbyte": 1024 * 1024 * 1024 * 1024,
+}
+
+// ParseValue membaca nilai tampilan PRTG seperti "1,234 kbit/s", "2.5 Gbit/s",
+// "12 %" atau "3 MByte" dan menormalisasinya berdasarkan jenis satuannya.
+// Koma dianggap pemisah ribuan, sesuai format default PRTG.
+func ParseValue(display string) (float64, string, error) {
+	match := valuePattern.FindStringSubmatch(display)
+	if match == nil {
+		return 0, "", fmt.Errorf("tidak ada bagian numerik yang ditemukan di '%s'", display)
+	}
+	value, err := strconv.ParseFloat(strings.ReplaceAll(match[1], ",", ""), 64)
+	if err != nil {
+		return 0, "", fmt.Errorf("gagal konversi nilai numerik '%s': %w", match[1], err)
+	}
+
+	unit := strings.ToLower(strings.TrimSpace(match[2]))
+	if unit == "%" {
+		return value, KindPercent, nil
+	}
+	if factor, ok := bitrateUnits[unit]; ok {
+		return value * factor, KindBitrate, nil
+	}
+	if factor, ok := byteUnits[strings.TrimSuffix(unit, "s")]; ok {
+		return value * factor, KindBytes, nil
+	}
+	return value, KindNumber, nil
+}
+
+// FormatValue menampilkan nilai hasil ParseValue beserta satuan normalnya.
+func FormatValue(value float64, kind string) string {
+	switch kind {
+	case KindBitrate:
+		return fmt.Sprintf("%.2f Kbit/s", value)
+	case KindPercent:
+		return fmt.Sprintf("%.2f %%", value)
+	case KindBytes:
+		if value >= 1024*1024 {
+			return fmt.Sprintf("%.2f MByte", value/(1024*1024))
+		}
+		return fmt.Sprintf("%.0f Byte", value)
+	default:
+		return strconv.FormatFloat(value, 'f', 2, 64)
+	}
+}
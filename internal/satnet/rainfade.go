@@ -0,0 +1,47 @@
+package satnet
+
+const (
+	CauseRainFade  = "RAIN_FADE"
+	CauseEquipment = "EQUIPMENT"
+	// CauseUnknown dipakai jika data Es/No dan beacon tidak cukup untuk menyimpulkan penyebab.
+	CauseUnknown = "UNKNOWN"
+)
+
+// BeaconReader membaca nilai CN beacon terkini dari hub.
+type BeaconReader func() (float64, error)
+
+// RainFadeDetector membedakan gangguan karena cuaca dari gangguan perangkat/jaringan.
+// Hujan di sisi terminal menurunkan Es/No terminal yang masih online secara bertahap,
+// sedangkan hujan di sisi gateway terlihat dari turunnya CN beacon. BeaconMin
+// adalah batas bawah beacon untuk gateway service ini.
+type RainFadeDetector struct {
+	EsnoDropDB float64
+	BeaconMin  float64
+}
+
+// Classify menyimpulkan penyebab gangguan dari data yang tersedia:
+//   - Es/No turun: rain fade (hujan di sisi terminal), apa pun nilai beacon.
+//   - Es/No stabil: gangguan perangkat, kecuali beacon rendah (bukti saling
+//     bertentangan, hasilnya unknown).
+//   - Es/No tidak tersedia (mis. semua terminal offline): rain fade jika beacon
+//     rendah, selain itu unknown.
+//
+// Data yang hilang tidak pernah dianggap sebagai gangguan perangkat.
+func (d RainFadeDetector) Classify(trend EsnoTrend, beacon *float64) string {
+	esnoKnown := trend.Baseline != nil && trend.Current != nil
+	esnoDropped := esnoKnown && *trend.Baseline-*trend.Current >= d.EsnoDropDB
+	beaconLow := beacon != nil && *beacon < d.BeaconMin
+
+	switch {
+	case esnoDropped:
+		return CauseRainFade
+	case esnoKnown && beaconLow:
+		return CauseUnknown
+	case esnoKnown:
+		return CauseEquipment
+	case beaconLow:
+		return CauseRainFade
+	default:
+		return CauseUnknown
+	}
+}
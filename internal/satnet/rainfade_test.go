@@ -0,0 +1,29 @@
+package satnet
+
+import "testing"
+
+func TestRainFadeClassify(t *testing.T) {
+	value := func(v float64) *float64 { return &v }
+	detector := RainFadeDetector{EsnoDropDB: 3, BeaconMin: 8}
+
+	tests := []struct {
+		name   string
+		trend  EsnoTrend
+		beacon *float64
+		want   string
+	}{
+		{"es/no turun, beacon normal", EsnoTrend{Baseline: value(12), Current: value(7)}, value(10), CauseRainFade},
+		{"es/no turun, beacon kosong", EsnoTrend{Baseline: value(12), Current: value(7)}, nil, CauseRainFade},
+		{"es/no stabil, beacon normal", EsnoTrend{Baseline: value(12), Current: value(11.5)}, value(10), CauseEquipment},
+		{"es/no stabil, beacon kosong", EsnoTrend{Baseline: value(12), Current: value(11.5)}, nil, CauseEquipment},
+		{"es/no stabil, beacon rendah", EsnoTrend{Baseline: value(12), Current: value(11.5)}, value(5), CauseUnknown},
+		{"es/no kosong, beacon rendah", EsnoTrend{Baseline: value(12)}, value(5), CauseRainFade},
+		{"es/no kosong, beacon normal", EsnoTrend{}, value(10), CauseUnknown},
+		{"semua data kosong", EsnoTrend{}, nil, CauseUnknown},
+	}
+	for _, tt := range tests {
+		if got := detector.Classify(tt.trend, tt.beacon); got != tt.want {
+			t.Errorf("%s: Classify = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
@@ -1,16 +1,33 @@
 package satnet
 
 import (
+	"context"
 	"fmt"
+	"log/slog"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
 )
 
+type TerminalCount struct {
+	Online  int64
+	Offline int64
+}
+
+// EsnoTrend berisi rata-rata Es/No terminal online pada window terkini dan baseline.
+type EsnoTrend struct {
+	Baseline *float64
+	Current  *float64
+}
+
 type Repository interface {
-	GetLastSatnetData() ([]Satnet, error)
-	GetStartIssueTime(satnetName string) (*time.Time, error)
-	GetTerminalStatus(satnetName string) (online *int64, offline *int64, err error)
+	GetLastSatnetData(ctx context.Context) ([]Satnet, error)
+	GetStartIssueTimes(ctx context.Context, satnetNames []string) (map[string]time.Time, error)
+	GetTerminalStatuses(ctx context.Context, satnetNames []string) (map[string]TerminalCount, error)
+	GetEsnoTrends(ctx context.Context, satnetNames []string) (map[string]EsnoTrend, error)
+	GetSatnetHistory(ctx context.Context, satnetName string, window time.Duration) ([]Satnet, error)
+	GetRecoveryTimes(ctx context.Context, startTimes map[string]time.Time) (map[string]time.Time, error)
 }
 
 type gormRepository struct {
@@ -30,7 +47,22 @@ type dbModel struct {
 
 func (dbModel) TableName() string { return "satnet_kpi" }
 
-func (r *gormRepository) GetLastSatnetData() ([]Satnet, error) {
+// dbTimestampLayout adalah format literal kolom timestamp (tanpa zona) PostgreSQL.
+const dbTimestampLayout = "2006-01-02 15:04:05.999999"
+
+// logQueryDuration mencatat durasi query di level Debug, atau Warn jika query
+// menghabiskan lebih dari separuh sisa batas waktu context saat dimulai.
+func logQueryDuration(ctx context.Context, query string, start time.Time, rows int) {
+	elapsed := time.Since(start)
+	if deadline, ok := ctx.Deadline(); ok && elapsed > deadline.Sub(start)/2 {
+		slog.Warn("Query database lambat", "query", query, "rows", rows, "duration_ms", elapsed.Milliseconds(), "budget_ms", deadline.Sub(start).Milliseconds())
+		return
+	}
+	slog.Debug("Query database selesai", "query", query, "rows", rows, "duration_ms", elapsed.Milliseconds())
+}
+
+func (r *gormRepository) GetLastSatnetData(ctx context.Context) ([]Satnet, error) {
+	start := time.Now()
 	var dbResults []dbModel
 	sql := `
 		SELECT DISTINCT ON (satnet_name)
@@ -41,9 +73,10 @@ func (r *gormRepository) GetLastSatnetData() ([]Satnet, error) {
 		FROM satnet_kpi
 		ORDER BY satnet_name, time DESC;
 	`
-	if err := r.db.Raw(sql).Scan(&dbResults).Error; err != nil {
+	if err := r.db.WithContext(ctx).Raw(sql).Scan(&dbResults).Error; err != nil {
 		return nil, fmt.Errorf("gagal query satnet_kpi: %w", err)
 	}
+	logQueryDuration(ctx, "GetLastSatnetData", start, len(dbResults))
 
 	results := make([]Satnet, len(dbResults))
 	for i, dbData := range dbResults {
@@ -57,56 +90,187 @@ func (r *gormRepository) GetLastSatnetData() ([]Satnet, error) {
 	return results, nil
 }
 
-func (r *gormRepository) GetStartIssueTime(satnetName string) (*time.Time, error) {
-	var result struct {
-		Time time.Time
+// GetStartIssueTimes mencari waktu awal gangguan (baris pertama di bawah
+// threshold setelah baris normal terakhir) untuk semua satnet sekaligus.
+func (r *gormRepository) GetStartIssueTimes(ctx context.Context, satnetNames []string) (map[string]time.Time, error) {
+	startTimes := make(map[string]time.Time)
+	if len(satnetNames) == 0 {
+		return startTimes, nil
+	}
+
+	start := time.Now()
+	var dbResults []struct {
+		SatnetName string    `gorm:"column:satnet_name"`
+		Time       time.Time `gorm:"column:time"`
 	}
 	sql := `
-		SELECT time FROM satnet_kpi
-		WHERE satnet_name = ? AND satnet_fwd_throughput < 1000
-		AND time >= (
-			SELECT time FROM satnet_kpi
-			WHERE satnet_name = ? AND satnet_fwd_throughput >= 1000
-			ORDER BY time DESC
-			LIMIT 1
+		WITH last_good AS (
+			SELECT satnet_name, MAX(time) AS time
+			FROM satnet_kpi
+			WHERE satnet_name IN ? AND satnet_fwd_throughput >= ?
+			GROUP BY satnet_name
 		)
-		ORDER BY time ASC
-		LIMIT 1;
+		SELECT k.satnet_name, MIN(k.time) AS time
+		FROM satnet_kpi k
+		JOIN last_good g ON g.satnet_name = k.satnet_name
+		WHERE k.satnet_fwd_throughput < ? AND k.time >= g.time
+		GROUP BY k.satnet_name;
 	`
-	err := r.db.Raw(sql, satnetName, satnetName).Scan(&result).Error
+	err := r.db.WithContext(ctx).Raw(sql, satnetNames, fwdThresholdKbps, fwdThresholdKbps).Scan(&dbResults).Error
 	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return nil, nil
-		}
-		return nil, err
+		return nil, fmt.Errorf("gagal query waktu awal gangguan satnet: %w", err)
 	}
-	if result.Time.IsZero() {
-		return nil, nil
+	logQueryDuration(ctx, "GetStartIssueTimes", start, len(dbResults))
+
+	for _, row := range dbResults {
+		if !row.Time.IsZero() {
+			startTimes[row.SatnetName] = row.Time
+		}
 	}
-	return &result.Time, nil
+	return startTimes, nil
 }
 
-func (r *gormRepository) GetTerminalStatus(satnetName string) (*int64, *int64, error) {
+// GetTerminalStatuses menghitung terminal online/offline pada snapshot
+// modem_kpi terbaru (maksimal 15 menit terakhir) untuk semua satnet sekaligus.
+func (r *gormRepository) GetTerminalStatuses(ctx context.Context, satnetNames []string) (map[string]TerminalCount, error) {
+	counts := make(map[string]TerminalCount)
+	if len(satnetNames) == 0 {
+		return counts, nil
+	}
 	if r.db == nil {
-		return nil, nil, fmt.Errorf("koneksi database (DB_FIVE) tidak tersedia")
+		return nil, fmt.Errorf("koneksi database (DB_FIVE) tidak tersedia")
 	}
 
-	var latestTime struct{ Time time.Time }
-	err := r.db.Table("modem_kpi").
-		Select("MAX(time) as time").
-		Where("satnet = ? AND time > NOW() - INTERVAL '15 minutes'", satnetName).
-		Scan(&latestTime).Error
+	start := time.Now()
+	var dbResults []struct {
+		Satnet  string `gorm:"column:satnet"`
+		Online  int64  `gorm:"column:online"`
+		Offline int64  `gorm:"column:offline"`
+	}
+	sql := `
+		WITH latest AS (
+			SELECT satnet, MAX(time) AS time
+			FROM modem_kpi
+			WHERE satnet IN ? AND time > NOW() - INTERVAL '15 minutes'
+			GROUP BY satnet
+		)
+		SELECT m.satnet,
+			COUNT(*) FILTER (WHERE m.esno_avg > 0) AS online,
+			COUNT(*) FILTER (WHERE m.esno_avg <= 0 OR m.esno_avg IS NULL) AS offline
+		FROM modem_kpi m
+		JOIN latest l ON l.satnet = m.satnet AND l.time = m.time
+		GROUP BY m.satnet;
+	`
+	if err := r.db.WithContext(ctx).Raw(sql, satnetNames).Scan(&dbResults).Error; err != nil {
+		return nil, fmt.Errorf("gagal query status terminal: %w", err)
+	}
+	logQueryDuration(ctx, "GetTerminalStatuses", start, len(dbResults))
+
+	for _, row := range dbResults {
+		counts[row.Satnet] = TerminalCount{Online: row.Online, Offline: row.Offline}
+	}
+	return counts, nil
+}
+
+// GetEsnoTrends membandingkan rata-rata esno_avg terminal online 15 menit
+// terakhir dengan baseline 1-3 jam sebelumnya untuk semua satnet sekaligus.
+func (r *gormRepository) GetEsnoTrends(ctx context.Context, satnetNames []string) (map[string]EsnoTrend, error) {
+	trends := make(map[string]EsnoTrend)
+	if len(satnetNames) == 0 {
+		return trends, nil
+	}
 
-	if err != nil || latestTime.Time.IsZero() {
-		if err == gorm.ErrRecordNotFound || latestTime.Time.IsZero() {
-			return nil, nil, nil
+	start := time.Now()
+	var dbResults []struct {
+		Satnet       string   `gorm:"column:satnet"`
+		BaselineEsno *float64 `gorm:"column:baseline_esno"`
+		CurrentEsno  *float64 `gorm:"column:current_esno"`
+	}
+	sql := `
+		SELECT satnet,
+			AVG(esno_avg) FILTER (WHERE time <= NOW() - INTERVAL '1 hour') AS baseline_esno,
+			AVG(esno_avg) FILTER (WHERE time > NOW() - INTERVAL '15 minutes') AS current_esno
+		FROM modem_kpi
+		WHERE satnet IN ? AND time > NOW() - INTERVAL '3 hours' AND esno_avg > 0
+		GROUP BY satnet;
+	`
+	if err := r.db.WithContext(ctx).Raw(sql, satnetNames).Scan(&dbResults).Error; err != nil {
+		return nil, fmt.Errorf("gagal query tren Es/No: %w", err)
+	}
+	logQueryDuration(ctx, "GetEsnoTrends", start, len(dbResults))
+
+	for _, row := range dbResults {
+		trends[row.Satnet] = EsnoTrend{Baseline: row.BaselineEsno, Current: row.CurrentEsno}
+	}
+	return trends, nil
+}
+
+func (r *gormRepository) GetSatnetHistory(ctx context.Context, satnetName string, window time.Duration) ([]Satnet, error) {
+	start := time.Now()
+	var dbResults []dbModel
+	sql := `
+		SELECT satnet_name, satnet_fwd_throughput, satnet_rtn_throughput, time
+		FROM satnet_kpi
+		WHERE satnet_name = ? AND time > NOW() - make_interval(secs => ?)
+		ORDER BY time ASC;
+	`
+	if err := r.db.WithContext(ctx).Raw(sql, satnetName, window.Seconds()).Scan(&dbResults).Error; err != nil {
+		return nil, fmt.Errorf("gagal query riwayat satnet_kpi: %w", err)
+	}
+	logQueryDuration(ctx, "GetSatnetHistory", start, len(dbResults))
+
+	results := make([]Satnet, len(dbResults))
+	for i, dbData := range dbResults {
+		results[i] = Satnet{
+			Name:          dbData.SatnetName,
+			FwdThroughput: dbData.SatnetFwdThroughput,
+			RtnThroughput: dbData.SatnetRtnThroughput,
+			Time:          dbData.Time,
 		}
-		return nil, nil, fmt.Errorf("gagal mendapatkan waktu terakhir data terminal: %w", err)
 	}
+	return results, nil
+}
 
-	var onlineCount, offlineCount int64
-	r.db.Table("modem_kpi").Where("satnet = ? AND time = ? AND esno_avg > 0", satnetName, latestTime.Time).Count(&onlineCount)
-	r.db.Table("modem_kpi").Where("satnet = ? AND time = ? AND (esno_avg <= 0 OR esno_avg IS NULL)", satnetName, latestTime.Time).Count(&offlineCount)
+// GetRecoveryTimes mencari baris satnet_kpi pertama dengan throughput normal
+// setelah waktu awal gangguan masing-masing satnet.
+func (r *gormRepository) GetRecoveryTimes(ctx context.Context, startTimes map[string]time.Time) (map[string]time.Time, error) {
+	recoveryTimes := make(map[string]time.Time)
+	if len(startTimes) == 0 {
+		return recoveryTimes, nil
+	}
 
-	return &onlineCount, &offlineCount, nil
+	start := time.Now()
+	values := make([]string, 0, len(startTimes))
+	args := make([]interface{}, 0, len(startTimes)*2+1)
+	for name, startTime := range startTimes {
+		// Kolom time menyimpan jam dinding WIB tanpa zona, dan startTime berasal
+		// dari kolom yang sama. Jam dinding dikirim sebagai teks agar tidak
+		// dikonversi ulang oleh zona waktu sesi database.
+		values = append(values, "(?, ?::timestamp)")
+		args = append(args, name, startTime.Format(dbTimestampLayout))
+	}
+	args = append(args, fwdThresholdKbps)
+
+	var dbResults []struct {
+		SatnetName string    `gorm:"column:satnet_name"`
+		Time       time.Time `gorm:"column:time"`
+	}
+	sql := fmt.Sprintf(`
+		SELECT k.satnet_name, MIN(k.time) AS time
+		FROM satnet_kpi k
+		JOIN (VALUES %s) AS s(satnet_name, start_time) ON s.satnet_name = k.satnet_name
+		WHERE k.time > s.start_time AND k.satnet_fwd_throughput >= ?
+		GROUP BY k.satnet_name;
+	`, strings.Join(values, ", "))
+	if err := r.db.WithContext(ctx).Raw(sql, args...).Scan(&dbResults).Error; err != nil {
+		return nil, fmt.Errorf("gagal query waktu pemulihan satnet: %w", err)
+	}
+	logQueryDuration(ctx, "GetRecoveryTimes", start, len(dbResults))
+
+	for _, row := range dbResults {
+		if !row.Time.IsZero() {
+			recoveryTimes[row.SatnetName] = row.Time
+		}
+	}
+	return recoveryTimes, nil
 }
@@ -1,11 +1,14 @@
 package satnet
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 	"strings"
 	"time"
 
+	configs "bella/config"
+	"bella/internal/freshness"
 	"bella/internal/notifier"
 	"bella/internal/state"
 	"bella/internal/types"
@@ -13,6 +16,8 @@ import (
 	"gorm.io/gorm"
 )
 
+const fwdThresholdKbps = 1000.0
+
 type Satnet struct {
 	Name          string
 	FwdThroughput float64
@@ -21,46 +26,59 @@ type Satnet struct {
 }
 
 type Service struct {
-	repo     Repository
-	notifier notifier.Notifier
-	state    *state.Manager
-	name     string
+	repo         Repository
+	notifier     notifier.Notifier
+	state        *state.Manager
+	freshness    *freshness.Checker
+	staleMaxAge  time.Duration
+	queryTimeout time.Duration
+	rainFade     RainFadeDetector
+	beacon       BeaconReader
+	name         string
 }
 
-func NewService(dbFive *gorm.DB, notifier notifier.Notifier, stateMgr *state.Manager, name string) *Service {
+func NewService(dbFive *gorm.DB, notifier notifier.Notifier, stateMgr *state.Manager, name string, config *configs.AppConfig, beacon BeaconReader) *Service {
 	return &Service{
-		repo:     NewGormRepository(dbFive),
-		notifier: notifier,
-		state:    stateMgr,
-		name:     name,
+		repo:         NewGormRepository(dbFive),
+		notifier:     notifier,
+		state:        stateMgr,
+		freshness:    freshness.NewChecker(notifier, stateMgr),
+		staleMaxAge:  config.StaleMaxAge("SATNET", "satnet_kpi", name),
+		queryTimeout: config.QueryTimeout,
+		rainFade: RainFadeDetector{
+			EsnoDropDB: config.RainFadeEsnoDropDB,
+			BeaconMin:  config.CNBeaconMinFor(name),
+		},
+		beacon: beacon,
+		name:   name,
 	}
 }
 
-func ParseStringToWIB(timeStr string) (time.Time, error) {
-	if timeStr == "" {
-		return time.Now(), nil
-	}
-	const layout = "2006-01-02T15:04:05"
-	loc, err := time.LoadLocation("Asia/Jakarta")
-	if err != nil {
-		return time.Time{}, err
-	}
-	if len(timeStr) < 19 {
-		return time.Time{}, fmt.Errorf("string waktu tidak valid: %s", timeStr)
-	}
-	return time.ParseInLocation(layout, timeStr[:19], loc)
-}
-
 func (s *Service) CheckAndAlert() {
 	slog.Info("Cron job terpicu, memulai pengecekan Satnet...", "gateway", s.name)
 
-	previousAlerts := s.state.GetActiveAlerts()
-	degradedSatnets, err := s.getCurrentDownSatnets()
+	ctx, cancel := context.WithTimeout(context.Background(), s.queryTimeout)
+	defer cancel()
+
+	allData, err := s.repo.GetLastSatnetData(ctx)
 	if err != nil {
 		slog.Error("Gagal mendapatkan status Satnet saat ini", "gateway", s.name, "error", err)
 		return
 	}
 
+	var latestData time.Time
+	for _, data := range allData {
+		if data.Time.After(latestData) {
+			latestData = data.Time
+		}
+	}
+	if !s.freshness.Evaluate("satnet_kpi", s.name, latestData, s.staleMaxAge) {
+		return
+	}
+
+	previousAlerts := s.state.GetActiveAlerts()
+	degradedSatnets := s.getCurrentDownSatnets(ctx, allData)
+
 	if len(degradedSatnets) > 0 {
 		slog.Info("Satnet terdeteksi DOWN, mengirim notifikasi...", "gateway", s.name, "count", len(degradedSatnets))
 		report := types.GatewayReport{FriendlyName: s.name, Satnets: degradedSatnets}
@@ -78,6 +96,12 @@ func (s *Service) CheckAndAlert() {
 		alertKey := s.getAlertKey(satnetDetail.Name)
 		if _, exists := previousAlerts[alertKey]; !exists {
 			slog.Info("Menambahkan Satnet DOWN baru ke state", "gateway", s.name, "satnet", satnetDetail.Name)
+			if satnetDetail.StartIssue == nil {
+				// Tanpa baris normal sebelumnya, baris rendah pertama yang terlihat dipakai sebagai awal gangguan.
+				if dataTime, err := time.Parse(time.RFC3339, satnetDetail.Time); err == nil {
+					satnetDetail.StartIssue = &dataTime
+				}
+			}
 			s.state.AddAlert(alertKey, state.ActiveAlert{
 				Type:    "satnet",
 				Gateway: s.name,
@@ -86,39 +110,68 @@ func (s *Service) CheckAndAlert() {
 		}
 	}
 
+	latestDataMap := make(map[string]time.Time)
+	for _, data := range allData {
+		latestDataMap[data.Name] = data.Time
+	}
+
+	recoveredStarts := make(map[string]time.Time)
+	recoveredKeys := make(map[string]string)
+	prefix := fmt.Sprintf("satnet_%s_", s.name)
+	for key, alert := range previousAlerts {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		satnetName := strings.TrimPrefix(key, prefix)
+		if _, stillDown := currentDownMap[satnetName]; stillDown {
+			continue
+		}
+
+		tStart, err := getStartIssue(alert)
+		if err != nil {
+			slog.Warn("Gagal membaca start_issue dari state", "key", key, "error", err)
+		}
+		recoveredStarts[satnetName] = tStart
+		recoveredKeys[satnetName] = key
+	}
+
+	recoveryTimes := make(map[string]time.Time)
+	if len(recoveredStarts) > 0 {
+		knownStarts := make(map[string]time.Time)
+		for name, tStart := range recoveredStarts {
+			if !tStart.IsZero() {
+				knownStarts[name] = tStart
+			}
+		}
+		recoveryTimes, err = s.repo.GetRecoveryTimes(ctx, knownStarts)
+		if err != nil {
+			slog.Warn("Gagal mendapatkan waktu pemulihan satnet", "gateway", s.name, "error", err)
+			recoveryTimes = make(map[string]time.Time)
+		}
+	}
+
 	var recoveredSatnets []types.SatnetUpAlert
-    prefix := fmt.Sprintf("satnet_%s_", s.name)
-    for key, alert := range previousAlerts {
-        if !strings.HasPrefix(key, prefix) {
-            continue
-        }
-        satnetName := strings.TrimPrefix(key, prefix)
-        if _, stillDown := currentDownMap[satnetName]; stillDown {
-            continue
-        }
-
-        m, ok := alert.Details.(map[string]interface{})
-        if !ok {
-            slog.Warn("Details bukan map[string]interface{}", "key", key)
-            continue
-        }
-        rawStart, _ := m["start_issue"].(string)
-        tStart, err := ParseStringToWIB(rawStart)
-        if err != nil {
-            slog.Warn("Gagal parse start_issue, gunakan time.Now()", "raw", rawStart, "err", err)
-            tStart = time.Now()
-        }
-
-        slog.Info("Satnet terdeteksi PULIH", "gateway", s.name, "satnet", satnetName)
-        recoveredSatnets = append(recoveredSatnets, types.SatnetUpAlert{
-            GatewayName:  s.name,
-            SatnetName:   satnetName,
-            RecoveryTime: time.Now(),
-            TimeDown:     tStart,
-        })
-
-        s.state.RemoveAlertByKey(key)
-    }
+	for satnetName, tStart := range recoveredStarts {
+		recoveryTime, ok := recoveryTimes[satnetName]
+		if !ok {
+			// Satnet dianggap pulih tanpa baris throughput normal (mis. terminal terdampak berkurang),
+			// sehingga baris data terbaru dipakai sebagai waktu pemulihan.
+			recoveryTime = latestDataMap[satnetName]
+		}
+		if recoveryTime.IsZero() {
+			recoveryTime = latestData
+		}
+
+		slog.Info("Satnet terdeteksi PULIH", "gateway", s.name, "satnet", satnetName)
+		recoveredSatnets = append(recoveredSatnets, types.SatnetUpAlert{
+			GatewayName:  s.name,
+			SatnetName:   satnetName,
+			RecoveryTime: recoveryTime,
+			TimeDown:     tStart,
+		})
+
+		s.state.RemoveAlertByKey(recoveredKeys[satnetName])
+	}
 	if len(recoveredSatnets) > 0 {
 		if err := s.notifier.SendSatnetUpAlert(recoveredSatnets); err != nil {
 			slog.Error("Gagal mengirim notifikasi Satnet UP", "gateway", s.name, "error", err)
@@ -126,46 +179,117 @@ func (s *Service) CheckAndAlert() {
 	}
 }
 
-func (s *Service) getCurrentDownSatnets() ([]types.SatnetDetail, error) {
-	const thresholdKbps = 1000.0
+func (s *Service) getCurrentDownSatnets(ctx context.Context, allData []Satnet) []types.SatnetDetail {
 	const alertThreshold = 3
 
-	allData, err := s.repo.GetLastSatnetData()
+	var candidates []Satnet
+	var candidateNames []string
+	for _, data := range allData {
+		if data.FwdThroughput < fwdThresholdKbps {
+			candidates = append(candidates, data)
+			candidateNames = append(candidateNames, data.Name)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	terminalCounts, err := s.repo.GetTerminalStatuses(ctx, candidateNames)
+	if err != nil {
+		slog.Warn("Gagal mendapatkan status terminal", "gateway", s.name, "error", err)
+	}
+	startIssueTimes, err := s.repo.GetStartIssueTimes(ctx, candidateNames)
 	if err != nil {
-		return nil, err
+		slog.Warn("Gagal mendapatkan waktu awal gangguan", "gateway", s.name, "error", err)
 	}
 
 	var degradedSatnetsForReport []types.SatnetDetail
-	for _, data := range allData {
-		if data.FwdThroughput < thresholdKbps {
-			online, offline, err := s.repo.GetTerminalStatus(data.Name)
-			if err != nil {
-				slog.Warn("Gagal mendapatkan status terminal", "gateway", s.name, "satnet", data.Name, "error", err)
-			}
+	for _, data := range candidates {
+		var online, offline *int64
+		if count, ok := terminalCounts[data.Name]; ok {
+			online = &count.Online
+			offline = &count.Offline
+		}
 
-			var totalAffected int64
-			if online != nil {
-				totalAffected += *online
-			}
-			if offline != nil {
-				totalAffected += *offline
-			}
+		var totalAffected int64
+		if online != nil {
+			totalAffected += *online
+		}
+		if offline != nil {
+			totalAffected += *offline
+		}
 
-			if totalAffected > alertThreshold {
-				startIssueTime, _ := s.repo.GetStartIssueTime(data.Name)
-				degradedSatnetsForReport = append(degradedSatnetsForReport, types.SatnetDetail{
-					Name:         data.Name,
-					FwdTp:        data.FwdThroughput,
-					RtnTp:        data.RtnThroughput,
-					Time:         data.Time.Format(time.RFC3339),
-					OnlineCount:  online,
-					OfflineCount: offline,
-					StartIssue:   startIssueTime,
-				})
+		if totalAffected > alertThreshold {
+			var startIssueTime *time.Time
+			if startTime, ok := startIssueTimes[data.Name]; ok {
+				startIssueTime = &startTime
 			}
+			degradedSatnetsForReport = append(degradedSatnetsForReport, types.SatnetDetail{
+				Name:         data.Name,
+				FwdTp:        data.FwdThroughput,
+				RtnTp:        data.RtnThroughput,
+				Time:         data.Time.Format(time.RFC3339),
+				OnlineCount:  online,
+				OfflineCount: offline,
+				StartIssue:   startIssueTime,
+			})
+		}
+	}
+	s.classifyIncidents(ctx, degradedSatnetsForReport)
+	return degradedSatnetsForReport
+}
+
+// classifyIncidents menandai setiap satnet yang down sebagai dugaan rain fade
+// atau gangguan perangkat/jaringan berdasarkan tren Es/No dan CN beacon.
+func (s *Service) classifyIncidents(ctx context.Context, satnets []types.SatnetDetail) {
+	if len(satnets) == 0 {
+		return
+	}
+
+	names := make([]string, len(satnets))
+	for i, satnet := range satnets {
+		names[i] = satnet.Name
+	}
+	trends, err := s.repo.GetEsnoTrends(ctx, names)
+	if err != nil {
+		slog.Warn("Gagal mendapatkan tren Es/No", "gateway", s.name, "error", err)
+	}
+
+	var beaconValue *float64
+	if s.beacon != nil {
+		if value, err := s.beacon(); err != nil {
+			slog.Warn("Gagal mendapatkan nilai CN beacon", "gateway", s.name, "error", err)
+		} else {
+			beaconValue = &value
+		}
+	}
+
+	for i := range satnets {
+		trend := trends[satnets[i].Name]
+		satnets[i].Cause = s.rainFade.Classify(trend, beaconValue)
+		satnets[i].EsnoBaseline = trend.Baseline
+		satnets[i].EsnoCurrent = trend.Current
+		satnets[i].CnBeacon = beaconValue
+		slog.Info("Klasifikasi gangguan satnet", "gateway", s.name, "satnet", satnets[i].Name, "cause", satnets[i].Cause)
+	}
+}
+
+func getStartIssue(alert state.ActiveAlert) (time.Time, error) {
+	switch details := alert.Details.(type) {
+	case types.SatnetDetail:
+		if details.StartIssue == nil {
+			return time.Time{}, fmt.Errorf("start_issue kosong")
+		}
+		return *details.StartIssue, nil
+	case map[string]interface{}:
+		rawStart, _ := details["start_issue"].(string)
+		if rawStart == "" {
+			return time.Time{}, fmt.Errorf("start_issue kosong")
 		}
+		return time.Parse(time.RFC3339Nano, rawStart)
+	default:
+		return time.Time{}, fmt.Errorf("format 'Details' tidak terduga: %T", alert.Details)
 	}
-	return degradedSatnetsForReport, nil
 }
 
 func (s *Service) getAlertKey(satnetName string) string {
@@ -0,0 +1,25 @@
+package state
+
+import "encoding/json"
+
+// DetailsAs membaca Details alert sebagai tipe T. Selama proses berjalan Details
+// masih berupa struct, tetapi setelah state dimuat ulang dari file menjadi
+// map[string]interface{}; bentuk itu didekode ulang lewat tag JSON milik T.
+// Nilai kembali kedua false jika Details kosong atau tidak dapat didekode.
+func DetailsAs[T any](alert ActiveAlert) (T, bool) {
+	if details, ok := alert.Details.(T); ok {
+		return details, true
+	}
+	var result T
+	if alert.Details == nil {
+		return result, false
+	}
+	data, err := json.Marshal(alert.Details)
+	if err != nil {
+		return result, false
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return result, false
+	}
+	return result, true
+}
@@ -0,0 +1,39 @@
+package state
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+type testDetails struct {
+	Severity  string    `json:"severity"`
+	StartTime time.Time `json:"start_time"`
+}
+
+func TestDetailsAs(t *testing.T) {
+	want := testDetails{Severity: "MAJOR", StartTime: time.Date(2025, 3, 1, 10, 30, 0, 123, time.FixedZone("WIB", 7*3600))}
+
+	got, ok := DetailsAs[testDetails](ActiveAlert{Details: want})
+	if !ok || got != want {
+		t.Errorf("DetailsAs(struct) = %+v, %v, want %+v, true", got, ok, want)
+	}
+
+	// Setelah dimuat ulang dari file, Details menjadi map[string]interface{}.
+	data, err := json.Marshal(ActiveAlert{Details: want})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var loaded ActiveAlert
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		t.Fatal(err)
+	}
+	got, ok = DetailsAs[testDetails](loaded)
+	if !ok || got.Severity != want.Severity || !got.StartTime.Equal(want.StartTime) {
+		t.Errorf("DetailsAs(map) = %+v, %v, want %+v, true", got, ok, want)
+	}
+
+	if _, ok := DetailsAs[testDetails](ActiveAlert{}); ok {
+		t.Error("DetailsAs(nil) ok = true, want false")
+	}
+}
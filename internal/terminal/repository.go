@@ -0,0 +1,92 @@
+package terminal
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type TerminalKPI struct {
+	Satnet     string    `gorm:"column:satnet"`
+	Name       string    `gorm:"column:modem_name"`
+	EsnoLatest *float64  `gorm:"column:esno_latest"`
+	EsnoMax    *float64  `gorm:"column:esno_max"`
+	Time       time.Time `gorm:"column:time"`
+}
+
+type Repository interface {
+	GetLatestKPITime(ctx context.Context) (time.Time, error)
+	GetTerminalKPIs(ctx context.Context, window time.Duration, terminalNames []string) ([]TerminalKPI, error)
+}
+
+type gormRepository struct {
+	db *gorm.DB
+}
+
+func NewGormRepository(db *gorm.DB) Repository {
+	return &gormRepository{db: db}
+}
+
+func (r *gormRepository) GetLatestKPITime(ctx context.Context) (time.Time, error) {
+	var result struct {
+		Time *time.Time `gorm:"column:time"`
+	}
+	if err := r.db.WithContext(ctx).Table("modem_kpi").Select("MAX(time) AS time").Scan(&result).Error; err != nil {
+		return time.Time{}, fmt.Errorf("gagal query waktu terakhir modem_kpi: %w", err)
+	}
+	if result.Time == nil {
+		return time.Time{}, nil
+	}
+	return *result.Time, nil
+}
+
+// GetTerminalKPIs mengambil snapshot terbaru setiap terminal beserta Es/No
+// tertinggi dalam window. Jika terminalNames kosong, semua terminal diambil.
+func (r *gormRepository) GetTerminalKPIs(ctx context.Context, window time.Duration, terminalNames []string) ([]TerminalKPI, error) {
+	start := time.Now()
+	var results []TerminalKPI
+
+	sql := `
+		WITH latest AS (
+			SELECT satnet, MAX(time) AS time
+			FROM modem_kpi
+			WHERE time > NOW() - INTERVAL '15 minutes'
+			GROUP BY satnet
+		), windowed AS (
+			SELECT modem_name, MAX(esno_avg) AS esno_max
+			FROM modem_kpi
+			WHERE time > NOW() - make_interval(secs => ?)
+			GROUP BY modem_name
+		)
+		SELECT m.satnet, m.modem_name, m.esno_avg AS esno_latest, w.esno_max, m.time
+		FROM modem_kpi m
+		JOIN latest l ON l.satnet = m.satnet AND l.time = m.time
+		LEFT JOIN windowed w ON w.modem_name = m.modem_name
+	`
+	args := []interface{}{window.Seconds()}
+	if len(terminalNames) > 0 {
+		sql += " WHERE m.modem_name IN ?"
+		args = append(args, terminalNames)
+	}
+	sql += " ORDER BY m.satnet, m.modem_name;"
+
+	if err := r.db.WithContext(ctx).Raw(sql, args...).Scan(&results).Error; err != nil {
+		return nil, fmt.Errorf("gagal query KPI terminal: %w", err)
+	}
+	logQueryDuration(ctx, "GetTerminalKPIs", start, len(results))
+	return results, nil
+}
+
+// logQueryDuration mencatat durasi query di level Debug, atau Warn jika query
+// menghabiskan lebih dari separuh sisa batas waktu context saat dimulai.
+func logQueryDuration(ctx context.Context, query string, start time.Time, rows int) {
+	elapsed := time.Since(start)
+	if deadline, ok := ctx.Deadline(); ok && elapsed > deadline.Sub(start)/2 {
+		slog.Warn("Query database lambat", "query", query, "rows", rows, "duration_ms", elapsed.Milliseconds(), "budget_ms", deadline.Sub(start).Milliseconds())
+		return
+	}
+	slog.Debug("Query database selesai", "query", query, "rows", rows, "duration_ms", elapsed.Milliseconds())
+}
@@ -0,0 +1,219 @@
+package terminal
+
+import (
+	configs "bella/config"
+	"bella/internal/freshness"
+	"bella/internal/notifier"
+	"bella/internal/state"
+	"bella/internal/types"
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	ConditionOffline  = "offline"
+	ConditionDegraded = "degraded"
+)
+
+type Service struct {
+	repo          Repository
+	notifier      notifier.Notifier
+	state         *state.Manager
+	freshness     *freshness.Checker
+	staleMaxAge   time.Duration
+	queryTimeout  time.Duration
+	watchlist     []string
+	esnoThreshold float64
+	esnoWindow    time.Duration
+	name          string
+}
+
+func NewService(dbFive *gorm.DB, notifier notifier.Notifier, stateMgr *state.Manager, name string, config *configs.AppConfig) *Service {
+	var watchlist []string
+	if !config.MonitorAllTerminals() {
+		watchlist = config.UTWatchlist
+	}
+	return &Service{
+		repo:          NewGormRepository(dbFive),
+		notifier:      notifier,
+		state:         stateMgr,
+		freshness:     freshness.NewChecker(notifier, stateMgr),
+		staleMaxAge:   config.StaleMaxAge("TERMINAL", "modem_kpi", name),
+		queryTimeout:  config.QueryTimeout,
+		watchlist:     watchlist,
+		esnoThreshold: config.UTEsnoThreshold,
+		esnoWindow:    config.UTEsnoWindow,
+		name:          name,
+	}
+}
+
+func (s *Service) CheckAndAlert() {
+	slog.Info("Cron job terpicu, memulai pengecekan terminal (UT)...", "gateway", s.name)
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.queryTimeout)
+	defer cancel()
+
+	latestTime, err := s.repo.GetLatestKPITime(ctx)
+	if err != nil {
+		slog.Error("Gagal mendapatkan waktu terakhir modem_kpi", "gateway", s.name, "error", err)
+		return
+	}
+	if !s.freshness.Evaluate("modem_kpi", s.name, latestTime, s.staleMaxAge) {
+		return
+	}
+
+	kpis, err := s.repo.GetTerminalKPIs(ctx, s.esnoWindow, s.watchlist)
+	if err != nil {
+		slog.Error("Gagal mendapatkan KPI terminal", "gateway", s.name, "error", err)
+		return
+	}
+
+	previousAlerts := s.state.GetActiveAlerts()
+	currentProblems := make(map[string]types.TerminalDownAlert)
+	problemTerminals := make(map[string]bool)
+	seenTerminals := make(map[string]TerminalKPI)
+
+	for _, kpi := range kpis {
+		seenTerminals[kpi.Name] = kpi
+		condition := s.classify(kpi)
+		if condition == "" {
+			continue
+		}
+		problemTerminals[kpi.Name] = true
+		currentProblems[s.getAlertKey(condition, kpi.Name)] = types.TerminalDownAlert{
+			GatewayName:  s.name,
+			SatnetName:   kpi.Satnet,
+			TerminalName: kpi.Name,
+			Condition:    condition,
+			EsnoAvg:      kpi.EsnoLatest,
+			StartTime:    kpi.Time,
+		}
+	}
+
+	newAlerts := make(map[string]types.TerminalDownAlert)
+	// replacedKeys memetakan key alert baru ke key kondisi sebelumnya milik
+	// terminal yang sama, agar tiap terminal hanya punya satu alert UT terbuka.
+	replacedKeys := make(map[string]string)
+	for key, alert := range currentProblems {
+		previousKey := s.getAlertKey(otherCondition(alert.Condition), alert.TerminalName)
+		previous, changed := previousAlerts[previousKey]
+		if _, exists := previousAlerts[key]; exists {
+			if changed {
+				// Sisa state lama dengan dua kondisi terbuka; cukup tutup yang tidak berlaku.
+				s.state.RemoveAlertByKey(previousKey)
+			}
+			continue
+		}
+		if changed {
+			slog.Info("Kondisi terminal berubah", "gateway", s.name, "terminal", alert.TerminalName, "from", otherCondition(alert.Condition), "to", alert.Condition)
+			if previousDetails, _ := state.DetailsAs[types.TerminalDownAlert](previous); !previousDetails.StartTime.IsZero() {
+				alert.StartTime = previousDetails.StartTime
+			}
+			replacedKeys[key] = previousKey
+		} else {
+			slog.Info("Terminal bermasalah baru terdeteksi", "gateway", s.name, "terminal", alert.TerminalName, "condition", alert.Condition)
+		}
+		newAlerts[key] = alert
+	}
+
+	recoveredAlerts := make(map[string]types.TerminalUpAlert)
+	for _, condition := range []string{ConditionOffline, ConditionDegraded} {
+		prefix := s.getAlertKey(condition, "")
+		for key, alert := range previousAlerts {
+			if !strings.HasPrefix(key, prefix) {
+				continue
+			}
+			if _, stillDown := currentProblems[key]; stillDown {
+				continue
+			}
+			terminalName := strings.TrimPrefix(key, prefix)
+			kpi, seen := seenTerminals[terminalName]
+			if !seen {
+				// Terminal tidak ada di snapshot terbaru, status pulih belum bisa dipastikan.
+				continue
+			}
+			if problemTerminals[terminalName] {
+				// Kondisi berpindah (mis. degraded ke offline), terminal belum pulih;
+				// key lama diganti bersama alert kondisi baru.
+				continue
+			}
+
+			slog.Info("Terminal terdeteksi PULIH", "gateway", s.name, "terminal", terminalName, "condition", condition)
+			details, _ := state.DetailsAs[types.TerminalDownAlert](alert)
+			recoveredAlerts[key] = types.TerminalUpAlert{
+				GatewayName:  s.name,
+				SatnetName:   kpi.Satnet,
+				TerminalName: terminalName,
+				Condition:    condition,
+				RecoveryTime: kpi.Time,
+				TimeDown:     details.StartTime,
+			}
+		}
+	}
+
+	// State baru diubah setelah notifikasi terkirim, sehingga notifikasi yang
+	// gagal dikirim ulang pada pengecekan berikutnya.
+	if len(newAlerts) > 0 {
+		alerts := make([]types.TerminalDownAlert, 0, len(newAlerts))
+		for _, alert := range newAlerts {
+			alerts = append(alerts, alert)
+		}
+		if err := s.notifier.SendTerminalDownAlert(alerts); err != nil {
+			slog.Error("Gagal mengirim notifikasi UT DOWN, akan dicoba lagi pada pengecekan berikutnya", "gateway", s.name, "error", err)
+		} else {
+			for key, alert := range newAlerts {
+				s.state.AddAlert(key, state.ActiveAlert{
+					Type:    "ut_" + alert.Condition,
+					Gateway: s.name,
+					Details: alert,
+				})
+				if previousKey, ok := replacedKeys[key]; ok {
+					s.state.RemoveAlertByKey(previousKey)
+				}
+			}
+		}
+	}
+	if len(recoveredAlerts) > 0 {
+		alerts := make([]types.TerminalUpAlert, 0, len(recoveredAlerts))
+		for _, alert := range recoveredAlerts {
+			alerts = append(alerts, alert)
+		}
+		if err := s.notifier.SendTerminalUpAlert(alerts); err != nil {
+			slog.Error("Gagal mengirim notifikasi UT UP, akan dicoba lagi pada pengecekan berikutnya", "gateway", s.name, "error", err)
+		} else {
+			for key := range recoveredAlerts {
+				s.state.RemoveAlertByKey(key)
+			}
+		}
+	}
+}
+
+// classify mengembalikan kondisi bermasalah terminal, atau string kosong jika normal.
+// Terminal dianggap degraded jika Es/No tertinggi selama window tetap di bawah threshold.
+func (s *Service) classify(kpi TerminalKPI) string {
+	if kpi.EsnoLatest == nil || *kpi.EsnoLatest <= 0 {
+		return ConditionOffline
+	}
+	if kpi.EsnoMax != nil && *kpi.EsnoMax < s.esnoThreshold {
+		return ConditionDegraded
+	}
+	return ""
+}
+
+// otherCondition mengembalikan kondisi bermasalah lain yang mungkin masih
+// tercatat untuk terminal yang sama.
+func otherCondition(condition string) string {
+	if condition == ConditionOffline {
+		return ConditionDegraded
+	}
+	return ConditionOffline
+}
+
+func (s *Service) getAlertKey(condition, terminalName string) string {
+	return fmt.Sprintf("ut_%s_%s_%s", condition, s.name, terminalName)
+}
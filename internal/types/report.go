@@ -15,6 +15,10 @@ type SatnetDetail struct {
 	OnlineCount  *int64     `json:"online_count"`
 	OfflineCount *int64     `json:"offline_count"`
 	StartIssue   *time.Time `json:"start_issue"`
+	Cause        string     `json:"cause,omitempty"`
+	EsnoBaseline *float64   `json:"esno_baseline,omitempty"`
+	EsnoCurrent  *float64   `json:"esno_current,omitempty"`
+	CnBeacon     *float64   `json:"cn_beacon,omitempty"`
 }
 
 type SatnetUpAlert struct {
@@ -28,9 +32,24 @@ type ModemDownAlert struct {
 	GatewayName string
 	DeviceName  string
 	AlarmState  string
+	Severity    string
+	Group       string
+	GroupDown   int
+	GroupSize   int
+	Impact      string
 	StartTime   time.Time
 }
 
+type ModemAlarmChangeAlert struct {
+	GatewayName   string
+	DeviceName    string
+	PreviousState string
+	CurrentState  string
+	Direction     string
+	ChangedAt     time.Time
+	StartTime     time.Time
+}
+
 type ModemUpAlert struct {
 	GatewayName  string
 	DeviceName   string
@@ -39,16 +58,18 @@ type ModemUpAlert struct {
 }
 
 type PRTGDownAlert struct {
-	Location       string `json:"location"`
-	SensorFullName string `json:"sensor_full_name"`
-	DeviceName     string `json:"device_name"`
-	SensorType     string `json:"sensor_type"`
-	Value          string `json:"value"`
-	Status         string `json:"status"`
-	LastMessage    string `json:"last_message"`
-	LastCheck      string `json:"last_check"`
-	LastDown       string `json:"last_down,omitempty"`
-	LastUp         string `json:"last_up,omitempty"`
+	Location       string    `json:"location"`
+	SensorFullName string    `json:"sensor_full_name"`
+	DeviceName     string    `json:"device_name"`
+	SensorType     string    `json:"sensor_type"`
+	Value          string    `json:"value"`
+	Status         string    `json:"status"`
+	Reason         string    `json:"reason,omitempty"`
+	LastMessage    string    `json:"last_message"`
+	LastCheck      string    `json:"last_check"`
+	LastDown       string    `json:"last_down,omitempty"`
+	LastUp         string    `json:"last_up,omitempty"`
+	DownSince      time.Time `json:"down_since"`
 }
 
 type PRTGUpAlert struct {
@@ -59,3 +80,110 @@ type PRTGUpAlert struct {
 	RecoveryTime   time.Time `json:"recovery_time"`
 	LastDown       time.Time `json:"last_down"`
 }
+
+// PRTGSensorChange adalah sensor yang ditambahkan atau dihapus oleh discovery PRTG.
+type PRTGSensorChange struct {
+	ID       string
+	Name     string
+	Gateway  string
+	Category string
+	// AlertClosed menandakan sensor yang dihapus masih memiliki alert aktif.
+	AlertClosed bool
+}
+
+type StaleDataAlert struct {
+	Source      string        `json:"source"`
+	GatewayName string        `json:"gateway_name"`
+	LastData    time.Time     `json:"last_data"`
+	MaxAge      time.Duration `json:"max_age"`
+	DetectedAt  time.Time     `json:"detected_at"`
+}
+
+type StaleDataUpAlert struct {
+	Source       string
+	GatewayName  string
+	StaleSince   time.Time
+	RecoveryTime time.Time
+}
+
+type TerminalDownAlert struct {
+	GatewayName  string    `json:"gateway_name"`
+	SatnetName   string    `json:"satnet_name"`
+	TerminalName string    `json:"terminal_name"`
+	Condition    string    `json:"condition"`
+	EsnoAvg      *float64  `json:"esno_avg"`
+	StartTime    time.Time `json:"start_time"`
+}
+
+type TerminalUpAlert struct {
+	GatewayName  string
+	SatnetName   string
+	TerminalName string
+	Condition    string
+	RecoveryTime time.Time
+	TimeDown     time.Time
+}
+
+type IpcnDeviceDownAlert struct {
+	GatewayName string    `json:"gateway_name"`
+	Category    string    `json:"category"`
+	DeviceName  string    `json:"device_name"`
+	Status      string    `json:"status"`
+	Severity    string    `json:"severity"`
+	GroupDown   int       `json:"group_down"`
+	GroupSize   int       `json:"group_size"`
+	Impact      string    `json:"impact"`
+	Escalated   bool      `json:"-"`
+	StartTime   time.Time `json:"start_time"`
+}
+
+type IpcnDeviceUpAlert struct {
+	GatewayName  string
+	Category     string
+	DeviceName   string
+	RecoveryTime time.Time
+	TimeDown     time.Time
+}
+
+// GatewayKPIAlert adalah KPI hub (CN beacon, beam offline, online UT) yang melewati batas.
+type GatewayKPIAlert struct {
+	Hub       string    `json:"hub"`
+	Metric    string    `json:"metric"`
+	Value     float64   `json:"value"`
+	Threshold float64   `json:"threshold"`
+	Baseline  float64   `json:"baseline,omitempty"`
+	StartTime time.Time `json:"start_time"`
+}
+
+type GatewayKPIUpAlert struct {
+	Hub          string
+	Metric       string
+	Value        float64
+	RecoveryTime time.Time
+	TimeDown     time.Time
+}
+
+// IpTransitAlert adalah perubahan kondisi IP transit gateway. Event bernilai
+// MAIN_DOWN (berjalan di backup), BOTH_DOWN, atau RESTORED (kembali ke main).
+type IpTransitAlert struct {
+	GatewayName  string    `json:"gateway_name"`
+	Event        string    `json:"event"`
+	Severity     string    `json:"severity"`
+	MainStatus   string    `json:"main_status"`
+	BackupStatus string    `json:"backup_status"`
+	StartTime    time.Time `json:"start_time"`
+	RecoveryTime time.Time `json:"recovery_time"`
+}
+
+type NmsDownAlert struct {
+	GatewayName string    `json:"gateway_name"`
+	Status      string    `json:"status"`
+	Severity    string    `json:"severity"`
+	StartTime   time.Time `json:"start_time"`
+}
+
+type NmsUpAlert struct {
+	GatewayName  string
+	RecoveryTime time.Time
+	TimeDown     time.Time
+}
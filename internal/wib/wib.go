@@ -0,0 +1,19 @@
+// Package wib menyediakan zona waktu WIB (Asia/Jakarta) yang dipakai untuk
+// timestamp alert dan perhitungan durasi di notifier.
+package wib
+
+import "time"
+
+// Location mengembalikan zona Asia/Jakarta, atau zona lokal jika data zona
+// waktu tidak tersedia.
+func Location() *time.Location {
+	if loc, err := time.LoadLocation("Asia/Jakarta"); err == nil {
+		return loc
+	}
+	return time.Local
+}
+
+// Now mengembalikan waktu sekarang dalam WIB.
+func Now() time.Time {
+	return time.Now().In(Location())
+}
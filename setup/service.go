@@ -1,20 +1,54 @@
 package setup
 
 import (
+	"bella/api"
+	"bella/bot"
 	config "bella/config"
 	"bella/db"
+	"bella/internal/freshness"
+	"bella/internal/history"
+	"bella/internal/ipcn"
+	"bella/internal/kpi"
 	"bella/internal/moddemod"
 	"bella/internal/notifier"
 	"bella/internal/prtgn"
 	"bella/internal/satnet"
 	"bella/internal/state"
+	"bella/internal/terminal"
+	"context"
 	"log/slog"
 
 	"github.com/robfig/cron/v3"
 	"gorm.io/gorm"
 )
 
-func RegisterServices(allConnections *db.Connections, notifier notifier.Notifier, stateMgr *state.Manager) map[string]*satnet.Service {
+// BuildAPIRegistry membuat klien API untuk setiap hub G1x dari konfigurasi.
+func BuildAPIRegistry(cfg *config.AppConfig) (*api.Registry, error) {
+	hubs := make([]api.HubConfig, 0, len(cfg.APIHubs))
+	for _, hub := range cfg.APIHubs {
+		if hub.TLSInsecure {
+			slog.Warn("Verifikasi sertifikat TLS hub dinonaktifkan", "hub", hub.Name, "url", hub.URL, "env", hub.Name+"_TLS_INSECURE")
+		}
+		hubs = append(hubs, api.HubConfig{
+			Name:               hub.Name,
+			BaseURL:            hub.URL,
+			Email:              hub.Email,
+			Password:           hub.Password,
+			InsecureSkipVerify: hub.TLSInsecure,
+			CAFile:             hub.CAFile,
+		})
+	}
+	return api.NewRegistry(hubs, api.ClientOptions{
+		Timeout:          cfg.APITimeout,
+		MaxRetries:       cfg.APIRetryMax,
+		Backoff:          cfg.APIRetryBackoff,
+		BreakerThreshold: cfg.APIBreakerThreshold,
+		BreakerCooldown:  cfg.APIBreakerCooldown,
+		CacheTTLs:        cfg.APICacheTTLs,
+	})
+}
+
+func RegisterServices(cfg *config.AppConfig, allConnections *db.Connections, apiClients *api.Registry, notifier notifier.Notifier, stateMgr *state.Manager) map[string]*satnet.Service {
 	slog.Info("Menginisialisasi semua service...")
 	serviceMap := make(map[string]*satnet.Service)
 
@@ -24,27 +58,127 @@ func RegisterServices(allConnections *db.Connections, notifier notifier.Notifier
 		"TIMIKA":    allConnections.DBFiveTMK,
 	}
 
+	// CN beacon diukur di hub pusat untuk seluruh gateway.
+	beaconReader := func() (float64, error) {
+		client, err := apiClients.Client(config.CentralHub)
+		if err != nil {
+			return 0, err
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.APITimeout)
+		defer cancel()
+		beacon, err := api.GetCnBeacon(ctx, client)
+		if err != nil {
+			return 0, err
+		}
+		return beacon.Data.Value, nil
+	}
+
 	for name, dbConn := range dbFiveMap {
 		if dbConn != nil {
-			serviceMap[name] = satnet.NewService(dbConn, notifier, stateMgr, name)
+			serviceMap[name] = satnet.NewService(dbConn, notifier, stateMgr, name, cfg, beaconReader)
 			slog.Info("Service Satnet untuk gateway berhasil dibuat.", "gateway", name)
 		}
 	}
 	return serviceMap
 }
 
-func RegisterCronJobs(scheduler *cron.Cron, config *config.AppConfig, serviceMap map[string]*satnet.Service, prtgAPI prtgn.PRTGAPIInterface, allConnections *db.Connections, notifier notifier.Notifier, stateMgr *state.Manager) {
+// BuildBotRepositories menyiapkan akses database per gateway untuk perintah bot.
+func BuildBotRepositories(allConnections *db.Connections, historyStore *history.Store, prtgAPI prtgn.PRTGAPIInterface) bot.Repositories {
+	repos := bot.Repositories{
+		Satnet:   make(map[string]satnet.Repository),
+		ModDemod: make(map[string]moddemod.Repository),
+		History:  historyStore,
+		PRTG:     prtgAPI,
+	}
+
+	dbFiveMap := map[string]*gorm.DB{
+		"JAYAPURA":  allConnections.DBFiveJYP,
+		"MANOKWARI": allConnections.DBFiveMNK,
+		"TIMIKA":    allConnections.DBFiveTMK,
+	}
+	for name, dbConn := range dbFiveMap {
+		if dbConn != nil {
+			repos.Satnet[name] = satnet.NewGormRepository(dbConn)
+		}
+	}
+
+	dbOneMap := map[string]*gorm.DB{
+		"JAYAPURA":  allConnections.DBOneJYP,
+		"MANOKWARI": allConnections.DBOneMNK,
+		"TIMIKA":    allConnections.DBOneTMK,
+	}
+	for name, dbConn := range dbOneMap {
+		if dbConn != nil {
+			repos.ModDemod[name] = moddemod.NewGormRepository(dbConn)
+		}
+	}
+	return repos
+}
+
+func RegisterCronJobs(scheduler *cron.Cron, cfg *config.AppConfig, serviceMap map[string]*satnet.Service, prtgAPI prtgn.PRTGAPIInterface, apiClients *api.Registry, allConnections *db.Connections, notifier notifier.Notifier, stateMgr *state.Manager, historyStore *history.Store) {
 	slog.Info("Mendaftarkan tugas-tugas cron...")
 
+	// Key alert stale untuk sumber database yang dipantau; sisanya dihapus di akhir.
+	staleSources := make(map[string]bool)
+
 	for name, service := range serviceMap {
 		svc := service
-		scheduler.AddFunc(config.CronSchedule, svc.CheckAndAlert)
+		scheduler.AddFunc(cfg.CronSchedule, svc.CheckAndAlert)
+		staleSources[freshness.StaleKey("satnet_kpi", name)] = true
 		slog.Info("Tugas cron Satnet berhasil didaftarkan.", "gateway", name)
 	}
 
 	if prtgAPI != nil {
-		scheduler.AddFunc(config.CronSchedule, prtgAPI.RunPeriodicChecks)
+		scheduler.AddFunc(cfg.CronSchedule, prtgAPI.RunPeriodicChecks)
 		slog.Info("Tugas cron untuk Pengecekan PRTG (NIF & IPTX) berhasil didaftarkan.")
+
+		if len(cfg.PRTGDiscoveryRules) > 0 {
+			prtgAPI.RunDiscovery()
+			if _, err := scheduler.AddFunc(cfg.PRTGDiscoverySchedule, prtgAPI.RunDiscovery); err != nil {
+				slog.Error("Gagal mendaftarkan tugas cron discovery PRTG", "schedule", cfg.PRTGDiscoverySchedule, "error", err)
+			} else {
+				slog.Info("Tugas cron discovery sensor PRTG berhasil didaftarkan.", "schedule", cfg.PRTGDiscoverySchedule)
+			}
+		}
+	}
+
+	// Status perangkat IPCN, IP transit, dan NMS dibaca dari hub yang melayani masing-masing gateway.
+	for name, hub := range config.GatewayHubs {
+		client, err := apiClients.Client(hub)
+		if err != nil {
+			slog.Error("Klien API hub tidak tersedia, pemantauan IPCN dilewati", "gateway", name, "hub", hub, "error", err)
+			continue
+		}
+		ipcnService := ipcn.NewService(client, notifier, stateMgr, name, cfg)
+		scheduler.AddFunc(cfg.CronSchedule, ipcnService.CheckAndAlert)
+		scheduler.AddFunc(cfg.CronSchedule, ipcnService.CheckTransitAndAlert)
+		slog.Info("Tugas cron pemantauan perangkat IPCN, IP transit, dan NMS berhasil didaftarkan.", "gateway", name)
+	}
+
+	// CN beacon, status beam, dan online UT diukur di hub pusat untuk seluruh gateway.
+	if client, err := apiClients.Client(config.CentralHub); err != nil {
+		slog.Error("Klien API hub pusat tidak tersedia, pemantauan KPI dilewati", "error", err)
+	} else {
+		kpiService := kpi.NewService(client, notifier, stateMgr, cfg)
+		scheduler.AddFunc(cfg.CronSchedule, kpiService.CheckAndAlert)
+		slog.Info("Tugas cron pemantauan KPI gateway berhasil didaftarkan.", "hub", client.Hub)
+	}
+
+	if len(cfg.UTWatchlist) > 0 {
+		dbFiveMap := map[string]*gorm.DB{
+			"JAYAPURA":  allConnections.DBFiveJYP,
+			"MANOKWARI": allConnections.DBFiveMNK,
+			"TIMIKA":    allConnections.DBFiveTMK,
+		}
+
+		for name, dbConn := range dbFiveMap {
+			if dbConn != nil {
+				terminalService := terminal.NewService(dbConn, notifier, stateMgr, name, cfg)
+				scheduler.AddFunc(cfg.CronSchedule, terminalService.CheckAndAlert)
+				staleSources[freshness.StaleKey("modem_kpi", name)] = true
+				slog.Info("Tugas cron pemantauan UT berhasil didaftarkan.", "gateway", name)
+			}
+		}
 	}
 
 	dbOneMap := map[string]*gorm.DB{
@@ -55,9 +189,17 @@ func RegisterCronJobs(scheduler *cron.Cron, config *config.AppConfig, serviceMap
 
 	for name, dbConn := range dbOneMap {
 		if dbConn != nil {
-			modemService := moddemod.NewService(dbConn, notifier, stateMgr, name)
-			scheduler.AddFunc(config.CronSchedule, modemService.CheckAndAlert)
+			modemService := moddemod.NewService(dbConn, notifier, stateMgr, name, cfg, historyStore)
+			scheduler.AddFunc(cfg.CronSchedule, modemService.CheckAndAlert)
+			staleSources[freshness.StaleKey("modulator", name)] = true
+			staleSources[freshness.StaleKey("demodulator", name)] = true
 			slog.Info("Tugas cron Modulator/Demodulator berhasil didaftarkan.", "gateway", name)
 		}
 	}
+
+	// Alert stale sensor PRTG dirapikan oleh PRTGAPI sendiri setelah discovery.
+	checker := freshness.NewChecker(notifier, stateMgr)
+	for _, source := range []string{"satnet_kpi", "modem_kpi", "modulator", "demodulator"} {
+		checker.Prune(source+"_", staleSources)
+	}
 }